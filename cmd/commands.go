@@ -0,0 +1,359 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/grindlemire/go-lucene"
+	"github.com/grindlemire/go-lucene/pkg/driver"
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+type command struct {
+	help string
+	// flags registers the command specific flags. It returns a function that builds the handler for
+	// the command once the flags have been parsed.
+	flags func(fs *flag.FlagSet) func() (handler, error)
+	// interactive commands parse their own flags and read their own input instead of running a
+	// handler on each query
+	interactive func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+}
+
+// handler turns a parsed query into its output. The output is either a string or a value that is
+// encoded as json.
+type handler func(e *expr.Expression) (out any, err error)
+
+var commands = map[string]command{
+	"parse": {
+		help:  "print the parsed query as a json syntax tree",
+		flags: parseCmd,
+	},
+	"render": {
+		help:  "render the query with a driver (--driver=postgres|mysql|sqlite|elasticsearch|mongo)",
+		flags: renderCmd,
+	},
+	"fmt": {
+		help:  "pretty print the query in canonical lucene syntax",
+		flags: fmtCmd,
+	},
+	"validate": {
+		help:  "check that the query parses, printing nothing for valid queries",
+		flags: validateCmd,
+	},
+	"explain": {
+		help:  "print the query as an indented tree of operators",
+		flags: explainCmd,
+	},
+	"repl": {
+		help:        "interactively show how queries are lexed, parsed, formatted and rendered",
+		interactive: repl,
+	},
+}
+
+func parseCmd(fs *flag.FlagSet) func() (handler, error) {
+	return handle(func(e *expr.Expression) (out any, err error) {
+		return e, nil
+	})
+}
+
+// handle builds a handler that doesn't depend on any flags
+func handle(h handler) func() (handler, error) {
+	return func() (handler, error) {
+		return h, nil
+	}
+}
+
+type sqlDriver interface {
+	Render(e *expr.Expression) (string, error)
+	RenderParam(e *expr.Expression) (string, []any, error)
+}
+
+type jsonDriver interface {
+	Render(e *expr.Expression) (string, error)
+}
+
+// paramsOutput is the output of render --params
+type paramsOutput struct {
+	Query  string `json:"query"`
+	Params []any  `json:"params"`
+}
+
+// String renders the query followed by its params
+func (p paramsOutput) String() string {
+	strs := make([]string, 0, len(p.Params))
+	for _, param := range p.Params {
+		strs = append(strs, fmt.Sprintf("%#v", param))
+	}
+	return fmt.Sprintf("%s\n[%s]", p.Query, strings.Join(strs, ", "))
+}
+
+func renderCmd(fs *flag.FlagSet) func() (handler, error) {
+	name := fs.String("driver", "postgres", "the driver to render with: postgres, mysql, sqlite, elasticsearch or mongo")
+	params := fs.Bool("params", false, "render sql with placeholders and print the params separately")
+
+	return func() (handler, error) {
+		return renderer(*name, *params)
+	}
+}
+
+// renderer builds a handler that renders queries with the named driver
+func renderer(name string, params bool) (h handler, err error) {
+	sqlDrivers := map[string]sqlDriver{
+		"postgres": driver.NewPostgresDriver(),
+		"mysql":    driver.NewMySQLDriver(),
+		"sqlite":   driver.NewSQLiteDriver(),
+	}
+	jsonDrivers := map[string]jsonDriver{
+		"elasticsearch": driver.NewElasticsearchDriver(),
+		"mongo":         driver.NewMongoDriver(),
+	}
+
+	if d, isSQL := sqlDrivers[name]; isSQL {
+		return func(e *expr.Expression) (out any, err error) {
+			if !params {
+				return d.Render(e)
+			}
+			s, vals, err := d.RenderParam(e)
+			return paramsOutput{Query: s, Params: vals}, err
+		}, nil
+	}
+
+	d, isJSON := jsonDrivers[name]
+	if !isJSON {
+		return nil, fmt.Errorf("unknown driver %q", name)
+	}
+	if params {
+		return nil, fmt.Errorf("the %s driver doesn't support --params", name)
+	}
+	return func(e *expr.Expression) (out any, err error) {
+		s, err := d.Render(e)
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(s), nil
+	}, nil
+}
+
+func fmtCmd(fs *flag.FlagSet) func() (handler, error) {
+	width := fs.Int("width", expr.DefaultWidth, "the line width to break long queries at")
+
+	return handle(func(e *expr.Expression) (out any, err error) {
+		return expr.Pretty(e, *width), nil
+	})
+}
+
+func validateCmd(fs *flag.FlagSet) func() (handler, error) {
+	return handle(func(e *expr.Expression) (out any, err error) {
+		return nil, nil
+	})
+}
+
+func explainCmd(fs *flag.FlagSet) func() (handler, error) {
+	return handle(func(e *expr.Expression) (out any, err error) {
+		return explain(e), nil
+	})
+}
+
+// explain renders the expression as a tree with one operator per line
+func explain(e *expr.Expression) string {
+	lines := []string{}
+	expr.Walk(e, func(c expr.Cursor) bool {
+		lines = append(lines, strings.Repeat("  ", c.Depth)+describe(c.Expr))
+		return true
+	}, nil)
+	return strings.Join(lines, "\n")
+}
+
+// describe renders a single expression without its children
+func describe(e *expr.Expression) string {
+	switch e.Op {
+	case expr.Literal:
+		if col, isCol := e.Left.(expr.Column); isCol {
+			return fmt.Sprintf("FIELD %s", string(col))
+		}
+		return fmt.Sprintf("%s %#v", e.Op, e.Left)
+	case expr.Wild, expr.Regexp:
+		return fmt.Sprintf("%s %v", e.Op, e.Left)
+	case expr.Boost:
+		return fmt.Sprintf("%s ^%v", e.Op, e.BoostPower())
+	case expr.Fuzzy:
+		return fmt.Sprintf("%s ~%d", e.Op, e.FuzzyDistance())
+	case expr.Proximity:
+		return fmt.Sprintf("%s ~%d", e.Op, e.ProximityDistance())
+	case expr.Range:
+		boundary, _ := e.Right.(*expr.RangeBoundary)
+		if boundary == nil {
+			break
+		}
+		open, closed := "exclusive", "exclusive"
+		if boundary.MinInclusive {
+			open = "inclusive"
+		}
+		if boundary.MaxInclusive {
+			closed = "inclusive"
+		}
+		return fmt.Sprintf("%s (%s min, %s max)", e.Op, open, closed)
+	}
+	return e.Op.String()
+}
+
+// result is the outcome of running a command on a single query
+type result struct {
+	Line   int    `json:"line"`
+	Query  string `json:"query"`
+	Output any    `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (c command) run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if c.interactive != nil {
+		return c.interactive(args, stdin, stdout, stderr)
+	}
+
+	fs := flag.NewFlagSet("lucene", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	output := fs.String("output", "text", "the output format: text or json")
+	batch := fs.Bool("batch", false, "read one query per line from stdin")
+	fields := fs.String("default-field", "", "a comma separated list of fields that bare terms are matched against")
+	build := c.flags(fs)
+
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	h, err := build()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+
+	queries, err := readQueries(fs.Args(), stdin, *batch)
+	if err != nil {
+		fmt.Fprintf(stderr, "error reading queries: %s\n", err)
+		return exitUsage
+	}
+
+	opts := []lucene.Option{}
+	if *fields != "" {
+		opts = append(opts, lucene.WithDefaultField(strings.Split(*fields, ",")...))
+	}
+
+	code := exitOK
+	for _, q := range queries {
+		res, resCode := runQuery(q, h, opts)
+		if resCode > code {
+			code = resCode
+		}
+
+		if *output == "json" {
+			raw, err := json.Marshal(res)
+			if err != nil {
+				fmt.Fprintf(stderr, "error encoding result for line %d: %s\n", res.Line, err)
+				code = exitRenderError
+				continue
+			}
+			fmt.Fprintln(stdout, string(raw))
+			continue
+		}
+
+		if res.Error != "" {
+			if len(queries) > 1 {
+				fmt.Fprintf(stderr, "line %d: ", res.Line)
+			}
+			fmt.Fprintln(stderr, res.Error)
+			continue
+		}
+		if res.Output != nil {
+			fmt.Fprintln(stdout, text(res.Output))
+		}
+	}
+	return code
+}
+
+// runQuery parses the query and runs the handler on it
+func runQuery(q query, h handler, opts []lucene.Option) (res result, code int) {
+	res = result{Line: q.line, Query: q.text}
+
+	e, err := lucene.Parse(q.text, opts...)
+	if err != nil {
+		res.Error = err.Error()
+		return res, exitParseError
+	}
+
+	res.Output, err = h(e)
+	if err != nil {
+		res.Output = nil
+		res.Error = err.Error()
+		return res, exitRenderError
+	}
+	return res, exitOK
+}
+
+// text renders the output of a command for a terminal
+func text(out any) string {
+	switch v := out.(type) {
+	case string:
+		return v
+	case *expr.Expression:
+		raw, _ := json.MarshalIndent(v, "", "  ")
+		return string(raw)
+	case json.RawMessage:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	raw, err := json.Marshal(out)
+	if err != nil {
+		return fmt.Sprintf("%v", out)
+	}
+	return string(raw)
+}
+
+type query struct {
+	line int
+	text string
+}
+
+// readQueries returns the queries passed as arguments or read from stdin
+func readQueries(args []string, stdin io.Reader, batch bool) (queries []query, err error) {
+	if len(args) > 0 {
+		for i, arg := range args {
+			queries = append(queries, query{line: i + 1, text: arg})
+		}
+		return queries, nil
+	}
+
+	if !batch {
+		raw, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []query{{line: 1, text: strings.TrimSpace(string(raw))}}, nil
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		queries = append(queries, query{line: line, text: text})
+	}
+	return queries, scanner.Err()
+}
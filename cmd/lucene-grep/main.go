@@ -0,0 +1,123 @@
+// lucene-grep filters JSON Lines on stdin with a lucene query and prints the lines that match.
+//
+//	lucene-grep 'level:error AND service:api*' < logs.jsonl
+//
+// Fields are resolved against each json object with dotted paths (e.g. http.status) reaching into
+// nested objects. Like grep it exits with 0 if any line was selected, 1 if none were and 2 if there
+// was an error.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/grindlemire/go-lucene"
+	"github.com/grindlemire/go-lucene/pkg/eval"
+)
+
+const (
+	exitMatch   = 0
+	exitNoMatch = 1
+	exitError   = 2
+)
+
+// maxLineSize is the longest json line we accept
+const maxLineSize = 16 * 1024 * 1024
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs lucene-grep with the arguments (without the program name) and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lucene-grep", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: lucene-grep [flags] <query> < input.jsonl\n\n")
+		fs.PrintDefaults()
+	}
+	count := fs.Bool("c", false, "only print the number of selected lines")
+	invert := fs.Bool("v", false, "select the lines that don't match the query")
+	fields := fs.String("default-field", "", "a comma separated list of fields that bare terms are matched against (defaults to every field)")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return exitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	opts := []lucene.Option{}
+	if *fields != "" {
+		opts = append(opts, lucene.WithDefaultField(strings.Split(*fields, ",")...))
+	}
+
+	e, err := lucene.Parse(fs.Arg(0), opts...)
+	if err != nil {
+		fmt.Fprintf(stderr, "lucene-grep: %s\n", err)
+		return exitError
+	}
+
+	match, err := eval.Compile(e)
+	if err != nil {
+		fmt.Fprintf(stderr, "lucene-grep: %s\n", err)
+		return exitError
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+
+	scanner := bufio.NewScanner(stdin)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	selected, failed := 0, false
+	for line := 1; scanner.Scan(); line++ {
+		raw := scanner.Bytes()
+		if len(strings.TrimSpace(string(raw))) == 0 {
+			continue
+		}
+
+		var record map[string]any
+		err := json.Unmarshal(raw, &record)
+		if err != nil {
+			fmt.Fprintf(stderr, "lucene-grep: line %d is not a json object: %s\n", line, err)
+			failed = true
+			continue
+		}
+
+		if match(record) == *invert {
+			continue
+		}
+
+		selected++
+		if !*count {
+			out.Write(raw)
+			out.WriteByte('\n')
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		fmt.Fprintf(stderr, "lucene-grep: error reading input: %s\n", err)
+		failed = true
+	}
+
+	if *count {
+		fmt.Fprintln(out, selected)
+	}
+
+	switch {
+	case failed:
+		return exitError
+	case selected == 0:
+		return exitNoMatch
+	}
+	return exitMatch
+}
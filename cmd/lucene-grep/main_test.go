@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const errTemplate = "%s:\n    wanted %q\n    got    %q"
+
+func TestRun(t *testing.T) {
+	type tc struct {
+		args       []string
+		wantOut    string
+		wantErr    string // a substring of stderr
+		wantStatus int
+	}
+
+	input := strings.Join([]string{
+		`{"level":"error","service":"api-gateway","msg":"upstream timed out","http":{"status":504}}`,
+		`{"level":"info","service":"api-gateway","msg":"request served","http":{"status":200}}`,
+		``,
+		`{"level":"error","service":"billing","msg":"card declined","http":{"status":402}}`,
+	}, "\n")
+
+	tcs := map[string]tc{
+		"match": {
+			args:    []string{"level:error AND service:api*"},
+			wantOut: `{"level":"error","service":"api-gateway","msg":"upstream timed out","http":{"status":504}}` + "\n",
+		},
+		"nested_field": {
+			args: []string{"http.status:>=400"},
+			wantOut: `{"level":"error","service":"api-gateway","msg":"upstream timed out","http":{"status":504}}` + "\n" +
+				`{"level":"error","service":"billing","msg":"card declined","http":{"status":402}}` + "\n",
+		},
+		"count": {
+			args:    []string{"-c", "level:error"},
+			wantOut: "2\n",
+		},
+		"invert": {
+			args:    []string{"-v", "level:error"},
+			wantOut: `{"level":"info","service":"api-gateway","msg":"request served","http":{"status":200}}` + "\n",
+		},
+		"default_field": {
+			args:    []string{"-default-field", "service", "billing"},
+			wantOut: `{"level":"error","service":"billing","msg":"card declined","http":{"status":402}}` + "\n",
+		},
+		"bare_term_matches_any_field": {
+			args:    []string{"-c", "billing"},
+			wantOut: "1\n",
+		},
+		"no_match": {
+			args:       []string{"level:debug"},
+			wantStatus: exitNoMatch,
+		},
+		"no_match_count": {
+			args:       []string{"-c", "level:debug"},
+			wantOut:    "0\n",
+			wantStatus: exitNoMatch,
+		},
+		"bad_query": {
+			args:       []string{"level:(error"},
+			wantErr:    "error parsing",
+			wantStatus: exitError,
+		},
+		"missing_query": {
+			args:       []string{},
+			wantErr:    "usage: lucene-grep",
+			wantStatus: exitError,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(tc.args, strings.NewReader(input), &stdout, &stderr)
+
+			if status != tc.wantStatus {
+				t.Fatalf("wanted exit code %d, got %d\nstderr: %s", tc.wantStatus, status, stderr.String())
+			}
+			if stdout.String() != tc.wantOut {
+				t.Fatalf(errTemplate, "stdout doesn't match", tc.wantOut, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tc.wantErr) {
+				t.Fatalf(errTemplate, "stderr doesn't match", tc.wantErr, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunInvalidJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	input := "{\"level\":\"error\"}\nnot json\n"
+
+	status := run([]string{"level:error"}, strings.NewReader(input), &stdout, &stderr)
+	if status != exitError {
+		t.Fatalf("wanted exit code %d, got %d", exitError, status)
+	}
+	if stdout.String() != "{\"level\":\"error\"}\n" {
+		t.Fatalf(errTemplate, "stdout doesn't match", "{\"level\":\"error\"}\n", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "line 2 is not a json object") {
+		t.Fatalf(errTemplate, "stderr doesn't match", "line 2 is not a json object", stderr.String())
+	}
+}
@@ -1,52 +1,74 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+)
 
-	"github.com/grindlemire/go-lucene"
-	"github.com/grindlemire/go-lucene/pkg/driver"
-	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+// exit codes so scripts can tell a bad query apart from a query that can't be rendered
+const (
+	exitOK          = 0
+	exitParseError  = 1
+	exitRenderError = 2
+	exitUsage       = 3
 )
 
+const usage = `usage: lucene <command> [flags] [query ...]
+
+Each query argument is handled separately. If no queries are given they are read from stdin,
+either as a single query or one per line with --batch.
+
+commands:
+%s
+exit codes:
+  0  success
+  1  a query could not be parsed
+  2  a query could not be rendered
+  3  bad usage
+
+When there are multiple queries the exit code is the highest of them.
+`
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Printf("Please provide a lucene query\n")
-		os.Exit(1)
-	}
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	e, err := lucene.Parse(os.Args[1])
-	if err != nil {
-		fmt.Printf("Error parsing: %s\n", err)
-		os.Exit(1)
+// run runs the cli with the arguments (without the program name) and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) < 1 {
+		fmt.Fprintf(stderr, usage, commandHelp())
+		return exitUsage
 	}
 
-	fmt.Printf("Parsed  input: %s\n", e)
-	fmt.Printf("Verbose input: %#v\n", e)
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprintf(stdout, usage, commandHelp())
+		return exitOK
+	}
 
-	s, err := json.MarshalIndent(e, "", "  ")
-	if err != nil {
-		fmt.Printf("Error marshalling to json: %s\n", err)
-		os.Exit(1)
+	cmd, found := commands[name]
+	if !found {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		fmt.Fprintf(stderr, usage, commandHelp())
+		return exitUsage
 	}
 
-	fmt.Printf("\n%s\n", s)
+	return cmd.run(args[1:], stdin, stdout, stderr)
+}
 
-	var e1 expr.Expression
-	err = json.Unmarshal(s, &e1)
-	if err != nil {
-		fmt.Printf("Error unmarshalling from json: %s\n", err)
-		os.Exit(1)
+func commandHelp() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	sq, err := driver.NewPostgresDriver().Render(e)
-	if err != nil {
-		fmt.Printf("Error rendering sql: %s\n", err)
-		os.Exit(1)
+	var sb strings.Builder
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", name, commands[name].help))
 	}
-
-	fmt.Printf("Reparsed input: %v\n", e1)
-	fmt.Printf("Verbose  input: %#v\n", e1)
-	fmt.Printf("SQL     output: %s\n", sq)
+	return sb.String()
 }
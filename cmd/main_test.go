@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const errTemplate = "%s:\n    wanted %q\n    got    %q"
+
+func TestRun(t *testing.T) {
+	type tc struct {
+		args       []string
+		stdin      string
+		wantOut    string
+		wantErr    string // a substring of stderr
+		wantStatus int
+	}
+
+	tcs := map[string]tc{
+		"parse_json": {
+			args:    []string{"parse", "--output", "json", "a:b"},
+			wantOut: `{"line":1,"query":"a:b","output":{"left":"a","operator":"EQUALS","right":"b"}}` + "\n",
+		},
+		"render_default_driver": {
+			args:    []string{"render", "a:b AND c:>1"},
+			wantOut: "(a = 'b') AND (c > 1)\n",
+		},
+		"render_params": {
+			args:    []string{"render", "--driver", "mysql", "--params", "a:b"},
+			wantOut: "a = ?\n[\"b\"]\n",
+		},
+		"render_json_driver": {
+			args:    []string{"render", "--driver=elasticsearch", "--output=json", "a:b"},
+			wantOut: `{"line":1,"query":"a:b","output":{"term":{"a":{"value":"b"}}}}` + "\n",
+		},
+		"render_error": {
+			args:       []string{"render", "a~"},
+			wantErr:    "unable to render operator [FUZZY]",
+			wantStatus: exitRenderError,
+		},
+		"fmt_from_stdin": {
+			args:    []string{"fmt", "--width", "10"},
+			stdin:   "a:b and (c:d || e:f)\n",
+			wantOut: "a:b\nAND (\n  c:d\n  OR e:f\n)\n",
+		},
+		"validate_batch": {
+			args:       []string{"validate", "--batch"},
+			stdin:      "a:b\n\nc:(d OR e\nf:g\n",
+			wantErr:    "line 3: error parsing",
+			wantStatus: exitParseError,
+		},
+		"explain": {
+			args:    []string{"explain", "--default-field", "title", "a AND NOT b:[1 TO *}"},
+			wantOut: "AND\n  EQUALS\n    FIELD title\n    LITERAL \"a\"\n  NOT\n    RANGE (inclusive min, exclusive max)\n      FIELD b\n      LITERAL 1\n      WILD *\n",
+		},
+		"multiple_queries_use_the_highest_exit_code": {
+			args:       []string{"render", "a:(b", "a~", "c:d"},
+			wantOut:    "c = 'd'\n",
+			wantErr:    "line 2: unable to render operator [FUZZY]",
+			wantStatus: exitRenderError,
+		},
+		"unknown_command": {
+			args:       []string{"frobnicate"},
+			wantErr:    `unknown command "frobnicate"`,
+			wantStatus: exitUsage,
+		},
+		"unknown_driver": {
+			args:       []string{"render", "--driver", "oracle", "a"},
+			wantErr:    `unknown driver "oracle"`,
+			wantStatus: exitUsage,
+		},
+		"unknown_output": {
+			args:       []string{"parse", "--output", "yaml", "a"},
+			wantErr:    `unknown output format "yaml"`,
+			wantStatus: exitUsage,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(tc.args, strings.NewReader(tc.stdin), &stdout, &stderr)
+
+			if status != tc.wantStatus {
+				t.Fatalf("wanted exit code %d, got %d\nstderr: %s", tc.wantStatus, status, stderr.String())
+			}
+			if stdout.String() != tc.wantOut {
+				t.Fatalf(errTemplate, "stdout doesn't match", tc.wantOut, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), tc.wantErr) {
+				t.Fatalf(errTemplate, "stderr doesn't match", tc.wantErr, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/grindlemire/go-lucene"
+	"github.com/grindlemire/go-lucene/internal/lex"
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+const replPrompt = "lucene> "
+
+const replHelp = `Enter a query to see how it is lexed, parsed, formatted and rendered.
+
+  :driver [name]  render with a driver (postgres, mysql, sqlite, elasticsearch or mongo), or off
+  :tokens         toggle printing the tokens from the lexer
+  :json           toggle printing the tree as json instead of indented operators
+  :history        list the queries entered so far
+  !!              run the last query again
+  !n              run query n from the history again
+  :help           print this help
+  :quit           exit
+`
+
+// replState holds the settings that the : commands change
+type replState struct {
+	driver  string // the driver to render with, empty when rendering is off
+	render  handler
+	tokens  bool
+	json    bool
+	opts    []lucene.Option
+	history []string
+	// historyFile is where queries are appended so they are kept across sessions
+	historyFile io.Writer
+}
+
+func repl(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lucene repl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("driver", "postgres", "the driver to render with: postgres, mysql, sqlite, elasticsearch, mongo or off")
+	fields := fs.String("default-field", "", "a comma separated list of fields that bare terms are matched against")
+	historyPath := fs.String("history", "", "a file to load the history from and save queries to")
+
+	err := fs.Parse(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(stderr, "repl reads queries from stdin and doesn't take any arguments")
+		return exitUsage
+	}
+
+	s := &replState{tokens: true}
+	err = s.setDriver(*name)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *fields != "" {
+		s.opts = append(s.opts, lucene.WithDefaultField(strings.Split(*fields, ",")...))
+	}
+
+	if *historyPath != "" {
+		s.history, err = loadHistory(*historyPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "error loading history: %s\n", err)
+			return exitUsage
+		}
+
+		f, err := os.OpenFile(*historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			fmt.Fprintf(stderr, "error opening history: %s\n", err)
+			return exitUsage
+		}
+		defer f.Close()
+		s.historyFile = f
+	}
+
+	scanner := bufio.NewScanner(stdin)
+	for {
+		fmt.Fprint(stdout, replPrompt)
+		if !scanner.Scan() {
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == ":quit" || line == ":q" {
+			return exitOK
+		}
+		s.handle(line, stdout)
+	}
+	fmt.Fprintln(stdout)
+
+	err = scanner.Err()
+	if err != nil {
+		fmt.Fprintf(stderr, "error reading input: %s\n", err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// handle runs a single line of input
+func (s *replState) handle(line string, out io.Writer) {
+	switch {
+	case line == "":
+		return
+	case isRecall(line):
+		q, err := s.recall(line)
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintln(out, q)
+		s.query(q, out)
+	case strings.HasPrefix(line, ":"):
+		s.command(line, out)
+	default:
+		s.query(line, out)
+	}
+}
+
+// command runs a : command that changes the repl settings
+func (s *replState) command(line string, out io.Writer) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case ":help":
+		fmt.Fprint(out, replHelp)
+	case ":tokens":
+		s.tokens = !s.tokens
+		fmt.Fprintf(out, "tokens %s\n", onOff(s.tokens))
+	case ":json":
+		s.json = !s.json
+		fmt.Fprintf(out, "json %s\n", onOff(s.json))
+	case ":driver":
+		if len(fields) == 1 {
+			fmt.Fprintf(out, "driver %s\n", s.driverName())
+			return
+		}
+		err := s.setDriver(fields[1])
+		if err != nil {
+			fmt.Fprintln(out, err)
+			return
+		}
+		fmt.Fprintf(out, "driver %s\n", s.driverName())
+	case ":history":
+		for i, q := range s.history {
+			fmt.Fprintf(out, "%4d  %s\n", i+1, q)
+		}
+	default:
+		fmt.Fprintf(out, "unknown command %s, try :help\n", fields[0])
+	}
+}
+
+// isRecall checks if the line is a history reference like !! or !3. Anything else starting with !
+// is a query using ! for NOT.
+func isRecall(line string) bool {
+	if line == "!!" {
+		return true
+	}
+	if len(line) < 2 || line[0] != '!' {
+		return false
+	}
+	for _, r := range line[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// recall finds the query that a ! reference points to in the history
+func (s *replState) recall(line string) (q string, err error) {
+	if len(s.history) == 0 {
+		return "", errors.New("the history is empty")
+	}
+	if line == "!!" {
+		return s.history[len(s.history)-1], nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 || n > len(s.history) {
+		return "", fmt.Errorf("%s is not in the history", line)
+	}
+	return s.history[n-1], nil
+}
+
+// query prints every stage of handling the query
+func (s *replState) query(q string, out io.Writer) {
+	s.remember(q)
+
+	if s.tokens {
+		fmt.Fprintln(out, "tokens:")
+		fmt.Fprintln(out, indent(tokens(q)))
+	}
+
+	e, err := lucene.Parse(q, s.opts...)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return
+	}
+
+	fmt.Fprintln(out, "tree:")
+	if s.json {
+		raw, _ := json.MarshalIndent(e, "", "  ")
+		fmt.Fprintln(out, indent(string(raw)))
+	} else {
+		fmt.Fprintln(out, indent(explain(e)))
+	}
+
+	fmt.Fprintln(out, "lucene:")
+	fmt.Fprintln(out, indent(expr.Format(e)))
+
+	if s.render == nil {
+		return
+	}
+	fmt.Fprintf(out, "%s:\n", s.driver)
+	rendered, err := s.render(e)
+	if err != nil {
+		fmt.Fprintln(out, indent(err.Error()))
+		return
+	}
+	fmt.Fprintln(out, indent(text(rendered)))
+}
+
+// remember adds the query to the history unless it repeats the last one
+func (s *replState) remember(q string) {
+	if len(s.history) > 0 && s.history[len(s.history)-1] == q {
+		return
+	}
+	s.history = append(s.history, q)
+	if s.historyFile != nil {
+		fmt.Fprintln(s.historyFile, q)
+	}
+}
+
+func (s *replState) setDriver(name string) error {
+	if name == "off" {
+		s.driver, s.render = "", nil
+		return nil
+	}
+
+	h, err := renderer(name, false)
+	if err != nil {
+		return err
+	}
+	s.driver, s.render = name, h
+	return nil
+}
+
+func (s *replState) driverName() string {
+	if s.driver == "" {
+		return "off"
+	}
+	return s.driver
+}
+
+// tokens lexes the query and renders one token per line with its offset
+func tokens(q string) string {
+	lines := []string{}
+	l := lex.Lex(q)
+	for {
+		tok := l.Next()
+		if tok.Typ == lex.TErr {
+			lines = append(lines, fmt.Sprintf("%-4d %-10s %s", tok.Pos(), tok.Typ, tok.Val))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%-4d %-10s %q", tok.Pos(), tok.Typ, tok.Val))
+		if tok.Typ == lex.TEOF {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// loadHistory reads the queries saved by earlier sessions, if there are any
+func loadHistory(path string) (history []string, err error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, nil
+}
+
+// indent indents every line of the string by two spaces
+func indent(s string) string {
+	return "  " + strings.ReplaceAll(s, "\n", "\n  ")
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPL(t *testing.T) {
+	type tc struct {
+		args      []string
+		stdin     string
+		wantOut   []string // substrings of stdout in order
+		wantNotIn []string
+	}
+
+	tcs := map[string]tc{
+		"every_stage": {
+			stdin: "a:b OR c\n",
+			wantOut: []string{
+				"tokens:\n  0    tLITERAL   \"a\"\n  1    tCOLON     \":\"\n  2    tLITERAL   \"b\"\n  4    tOR        \"OR\"\n  7    tLITERAL   \"c\"\n  8    tEOF       \"EOF\"\n",
+				"tree:\n  OR\n    EQUALS\n      FIELD a\n      LITERAL \"b\"\n    LITERAL \"c\"\n",
+				"lucene:\n  a:b OR c\n",
+				"postgres:\n  (a = 'b') OR ('c')\n",
+			},
+		},
+		"toggles": {
+			stdin: ":tokens\n:json\n:driver mongo\na:b\n",
+			wantOut: []string{
+				"tokens off",
+				"json on",
+				"driver mongo",
+				"tree:\n  {\n    \"left\": \"a\",\n    \"operator\": \"EQUALS\",\n    \"right\": \"b\"\n  }\n",
+				"mongo:\n  {\"a\":{\"$eq\":\"b\"}}\n",
+			},
+			wantNotIn: []string{"tokens:"},
+		},
+		"driver_off": {
+			args:      []string{"--driver", "off"},
+			stdin:     "a:b\n:driver\n",
+			wantOut:   []string{"lucene:\n  a:b\n", "driver off"},
+			wantNotIn: []string{"postgres:"},
+		},
+		"unknown_driver_keeps_the_old_one": {
+			stdin:   ":driver oracle\n:driver\n",
+			wantOut: []string{`unknown driver "oracle"`, "driver postgres"},
+		},
+		"parse_error_shows_tokens": {
+			stdin:     "a:(b\n",
+			wantOut:   []string{"tLPAREN", "error parsing"},
+			wantNotIn: []string{"tree:"},
+		},
+		"history": {
+			stdin: "a:b\nc:d\nc:d\n:history\n!1\n!!\n!5\n",
+			wantOut: []string{
+				"   1  a:b\n   2  c:d\n",
+				replPrompt + "a:b\ntokens:",
+				replPrompt + "a:b\ntokens:",
+				"!5 is not in the history",
+			},
+		},
+		"negated_query_is_not_a_recall": {
+			args:  []string{"--driver", "off"},
+			stdin: "!c:d AND a:b\n",
+			wantOut: []string{
+				"tNOT",
+				"tree:\n  AND\n    NOT\n      EQUALS\n        FIELD c\n",
+				"lucene:\n  NOT c:d AND a:b\n",
+			},
+			wantNotIn: []string{"history"},
+		},
+		"quit": {
+			stdin:     ":tokens\n:quit\na:b\n",
+			wantOut:   []string{"tokens off"},
+			wantNotIn: []string{"tree:"},
+		},
+		"unknown_command": {
+			stdin:   ":nope\n",
+			wantOut: []string{"unknown command :nope, try :help"},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(append([]string{"repl"}, tc.args...), strings.NewReader(tc.stdin), &stdout, &stderr)
+			if status != exitOK {
+				t.Fatalf("wanted exit code %d, got %d\nstderr: %s", exitOK, status, stderr.String())
+			}
+
+			out := stdout.String()
+			rest := out
+			for _, want := range tc.wantOut {
+				i := strings.Index(rest, want)
+				if i < 0 {
+					t.Fatalf(errTemplate, "stdout is missing output", want, out)
+				}
+				rest = rest[i+len(want):]
+			}
+			for _, notWant := range tc.wantNotIn {
+				if strings.Contains(out, notWant) {
+					t.Fatalf("stdout shouldn't contain %q:\n%s", notWant, out)
+				}
+			}
+		})
+	}
+}
+
+func TestREPLHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	err := os.WriteFile(path, []byte("a:b\n"), 0o600)
+	if err != nil {
+		t.Fatalf("unable to write history: %s", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"repl", "--history", path}, strings.NewReader("c:d\n:history\n"), &stdout, &stderr)
+	if status != exitOK {
+		t.Fatalf("wanted exit code %d, got %d\nstderr: %s", exitOK, status, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "   1  a:b\n   2  c:d\n") {
+		t.Fatalf(errTemplate, "history doesn't match", "   1  a:b\n   2  c:d\n", stdout.String())
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read history: %s", err)
+	}
+	if string(raw) != "a:b\nc:d\n" {
+		t.Fatalf(errTemplate, "history file doesn't match", "a:b\nc:d\n", string(raw))
+	}
+}
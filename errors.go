@@ -0,0 +1,130 @@
+package lucene
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/grindlemire/go-lucene/internal/lex"
+)
+
+// ParseError is returned when a query can't be parsed. It carries the position of the offending
+// token in the input so callers can point the user at the problem.
+type ParseError struct {
+	Msg      string // a description of what went wrong
+	Offset   int    // the byte offset of the offending token in the input
+	Line     int    // the line of the offending token, starting at 1
+	Column   int    // the column of the offending token in runes, starting at 1
+	Token    string // the offending token, empty at the end of the input
+	Expected string // a hint for what was expected at the offset, if known
+	Err      error  // the underlying error when the query parsed but failed validation
+}
+
+// Error renders the parse error with its position
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("error parsing at line %d, column %d", e.Line, e.Column))
+	if e.Token != "" {
+		sb.WriteString(fmt.Sprintf(" near %q", e.Token))
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.Msg)
+	if e.Expected != "" {
+		sb.WriteString(fmt.Sprintf(" (expected %s)", e.Expected))
+	}
+	return sb.String()
+}
+
+// Unwrap returns the validation error behind the parse error, if there is one
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// LimitError is returned when a query exceeds one of the complexity limits passed to Parse.
+// It is distinct from a ParseError since the query is valid but too expensive to run.
+type LimitError struct {
+	Limit  string // the name of the limit that was exceeded
+	Max    int    // the configured maximum, 0 for limits that forbid something outright
+	Actual int    // how far the query got when it exceeded the maximum
+	Offset int    // the byte offset in the input where the limit was exceeded or -1 if it isn't known
+}
+
+// Error renders the limit error
+func (e *LimitError) Error() string {
+	msg := fmt.Sprintf("query exceeds the maximum %s of %d", e.Limit, e.Max)
+	if e.Max == 0 {
+		msg = fmt.Sprintf("query is not allowed to use a %s", e.Limit)
+	}
+
+	if e.Offset < 0 {
+		return msg
+	}
+	return fmt.Sprintf("%s (at offset %d)", msg, e.Offset)
+}
+
+// expectedAfter are hints for what should follow a non terminal when the parser gets stuck on it
+var expectedAfter = map[lex.TokType]string{
+	lex.TColon:   "a value",
+	lex.TEqual:   "a value",
+	lex.TGreater: "a value",
+	lex.TLess:    "a value",
+	lex.TPlus:    "a term",
+	lex.TMinus:   "a term",
+	lex.TNot:     "a term",
+	lex.TAnd:     "a term",
+	lex.TOr:      "a term",
+	lex.TTilde:   "a term before '~'",
+	lex.TCarrot:  "a term before '^'",
+	lex.TLParen:  "')'",
+	lex.TRParen:  "a matching '('",
+	lex.TLSquare: "a range like [a TO b]",
+	lex.TLCurly:  "a range like {a TO b}",
+	lex.TRSquare: "a range like [a TO b]",
+	lex.TRCurly:  "a range like {a TO b}",
+	lex.TTO:      "a range boundary",
+	lex.TStart:   "a term",
+}
+
+// newParseError builds a parse error pointing at the token
+func newParseError(input string, tok lex.Token, expected string, format string, args ...any) *ParseError {
+	token := ""
+	switch tok.Typ {
+	case lex.TEOF:
+	case lex.TErr:
+		// the value of an error token is the error message so use the rest of the input
+		if tok.Pos() < len(input) {
+			token = input[tok.Pos():]
+		}
+	default:
+		token = tok.Val
+	}
+
+	return newParseErrorAt(input, tok.Pos(), token, expected, fmt.Sprintf(format, args...))
+}
+
+// newParseErrorAt builds a parse error pointing at a byte offset in the input
+func newParseErrorAt(input string, offset int, token, expected, msg string) *ParseError {
+	if offset > len(input) {
+		offset = len(input)
+	}
+
+	line := strings.Count(input[:offset], "\n") + 1
+	lineStart := strings.LastIndex(input[:offset], "\n") + 1
+
+	return &ParseError{
+		Msg:      msg,
+		Offset:   offset,
+		Line:     line,
+		Column:   utf8.RuneCountInString(input[lineStart:offset]) + 1,
+		Token:    token,
+		Expected: expected,
+	}
+}
+
+// found describes the token the parser got stuck on
+func found(tok lex.Token) string {
+	if tok.Typ == lex.TEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", tok.Val)
+}
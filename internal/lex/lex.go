@@ -2,6 +2,7 @@ package lex
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -27,6 +28,17 @@ func (i Token) String() string {
 	return fmt.Sprintf("%q", i.Val)
 }
 
+// Pos returns the byte offset of the token in the input
+func (i Token) Pos() int {
+	return i.pos
+}
+
+// NewToken creates a token at a byte offset in the input. It is used for tokens the parser implies
+// rather than reads, like the AND between two clauses.
+func NewToken(typ TokType, val string, pos int) Token {
+	return Token{Typ: typ, pos: pos, Val: val}
+}
+
 // precedance : > ) > + > - > ~ > ^ > NOT > AND > OR > (
 
 // TokType is an enum of token types that can be parsed by the lexer. Order matters here for non terminals
@@ -216,6 +228,8 @@ func lexSpace(l *Lexer) tokenStateFn {
 	for {
 		switch l.next() {
 		case eof:
+			// the EOF token sits after any trailing whitespace
+			l.currItem.pos = l.pos
 			return nil
 		case ' ', '\t', '\r', '\n':
 			continue
@@ -243,6 +257,15 @@ func lexVal(l *Lexer) tokenStateFn {
 		l.backup()
 		return lexWord
 
+	// symbolic boolean operators are aliases for the keywords
+	case r == '!':
+		return l.emit(TNot)
+	case (r == '&' || r == '|') && l.peek() == r:
+		l.next()
+		if r == '&' {
+			return l.emit(TAnd)
+		}
+		return l.emit(TOr)
 	case r == '"' || r == '\'':
 		l.backup()
 		return lexPhrase
@@ -296,6 +319,11 @@ loop:
 		switch r := l.next(); {
 		case isAlphaNumeric(r) || isWildcard(r) || r == '.' || r == '-':
 			// do nothing
+		case continuesDate(l.input[l.start:l.pos-1], r, l.peek()):
+			// dates can contain characters that are otherwise symbols
+		case r == '|' && anchorsDateMath(l.input[l.start:l.pos-1], l.input[l.pos:]):
+			// the || in 2024-01-31||+1M/M anchors the date math rather than being an OR
+			l.next()
 		case isEscape(r):
 			l.next() // just ignore the next character
 		default:
@@ -353,7 +381,13 @@ func (l *Lexer) next() rune {
 
 // peek returns but does not consume the next rune in the input.
 func (l *Lexer) peek() rune {
+	atEOF := l.atEOF
 	r := l.next()
+	if r == eof {
+		// peeking at the end of the input shouldn't stop us from backing up
+		l.atEOF = atEOF
+		return r
+	}
 	l.backup()
 	return r
 }
@@ -400,6 +434,47 @@ func isEscape(r rune) bool {
 	return r == '\\'
 }
 
+var (
+	// isoDateTime matches the start of an ISO-8601 date time up to a : or +
+	isoDateTime = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T[\d:.+-]*$`)
+	// dateMath matches the start of an elasticsearch date math expression up to a + or /, either
+	// relative to now or anchored to a date with ||
+	dateMath = regexp.MustCompile(`^(now|\d{4}-\d{2}-\d{2}(T[\dZ:.+-]*)?\|\|)[\dyMwdhHms+/-]*$`)
+	// anchorDate matches a date that date math can be anchored to
+	anchorDate = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T[\dZ:.+-]*)?$`)
+)
+
+// continuesDate checks whether the rune continues a date in the current word. This lets times like
+// 2024-01-01T10:00:00+02:00 and date math like now+1d/d be lexed as a single word.
+func continuesDate(word string, r rune, next rune) bool {
+	switch r {
+	case ':':
+		return isoDateTime.MatchString(word) && unicode.IsDigit(next)
+	case '+':
+		return (isoDateTime.MatchString(word) || dateMath.MatchString(word)) && unicode.IsDigit(next)
+	case '/':
+		return dateMath.MatchString(word) && strings.ContainsRune("yMwdhHms", next)
+	}
+	return false
+}
+
+// anchorsDateMath checks whether the | that ended the word starts a || that anchors date math to the
+// date in the word. The rest of the input comes after the first | and needs to hold the second | and
+// a date math operation like +1M or /d.
+func anchorsDateMath(word string, rest string) bool {
+	if !anchorDate.MatchString(word) || len(rest) < 3 || rest[0] != '|' {
+		return false
+	}
+
+	switch rest[1] {
+	case '+', '-':
+		return unicode.IsDigit(rune(rest[2]))
+	case '/':
+		return strings.ContainsRune("yMwdhHms", rune(rest[2]))
+	}
+	return false
+}
+
 // isSymbol checks whether the run is one of the reserved symbols
 func isSymbol(r rune) bool {
 	_, found := symbols[r]
@@ -62,6 +62,12 @@ func TestLex(t *testing.T) {
 				tok(TLiteral, "c"),
 			},
 		},
+		"trailing_whitespace_before_eof": {
+			in: "ab \t",
+			expected: []Token{
+				tok(TLiteral, "ab"),
+			},
+		},
 		"quotes_single_token": {
 			in: `"abc"`,
 			expected: []Token{
@@ -133,6 +139,94 @@ func TestLex(t *testing.T) {
 				tok(TLiteral, "a"),
 			},
 		},
+		"symbolic_booleans_tokenized": {
+			in: `a && b || !c`,
+			expected: []Token{
+				tok(TLiteral, "a"),
+				tok(TAnd, "&&"),
+				tok(TLiteral, "b"),
+				tok(TOr, "||"),
+				tok(TNot, "!"),
+				tok(TLiteral, "c"),
+			},
+		},
+		"iso_date_time": {
+			in: `a:2024-01-01T10:00:00.5+02:00 AND b`,
+			expected: []Token{
+				tok(TLiteral, "a"),
+				tok(TColon, ":"),
+				tok(TLiteral, "2024-01-01T10:00:00.5+02:00"),
+				tok(TAnd, "AND"),
+				tok(TLiteral, "b"),
+			},
+		},
+		"date_math": {
+			in: `a:[now-1M+2d/d TO now/h]`,
+			expected: []Token{
+				tok(TLiteral, "a"),
+				tok(TColon, ":"),
+				tok(TLSquare, "["),
+				tok(TLiteral, "now-1M+2d/d"),
+				tok(TTO, "TO"),
+				tok(TLiteral, "now/h"),
+				tok(TRSquare, "]"),
+			},
+		},
+		"anchored_date_math": {
+			in: `a:2024-01-31||+1M/M OR b:[2024-01-01 TO 2024-01-31||+1M]`,
+			expected: []Token{
+				tok(TLiteral, "a"),
+				tok(TColon, ":"),
+				tok(TLiteral, "2024-01-31||+1M/M"),
+				tok(TOr, "OR"),
+				tok(TLiteral, "b"),
+				tok(TColon, ":"),
+				tok(TLSquare, "["),
+				tok(TLiteral, "2024-01-01"),
+				tok(TTO, "TO"),
+				tok(TLiteral, "2024-01-31||+1M"),
+				tok(TRSquare, "]"),
+			},
+		},
+		"or_after_a_date": {
+			in: `a:2024-01-31||b:c`,
+			expected: []Token{
+				tok(TLiteral, "a"),
+				tok(TColon, ":"),
+				tok(TLiteral, "2024-01-31"),
+				tok(TOr, "||"),
+				tok(TLiteral, "b"),
+				tok(TColon, ":"),
+				tok(TLiteral, "c"),
+			},
+		},
+		"symbols_after_words_are_not_dates": {
+			in: `now+b c:+d`,
+			expected: []Token{
+				tok(TLiteral, "now"),
+				tok(TPlus, "+"),
+				tok(TLiteral, "b"),
+				tok(TLiteral, "c"),
+				tok(TColon, ":"),
+				tok(TPlus, "+"),
+				tok(TLiteral, "d"),
+			},
+		},
+		"symbolic_booleans_without_spaces": {
+			in: `a:b&&!c:d||e`,
+			expected: []Token{
+				tok(TLiteral, "a"),
+				tok(TColon, ":"),
+				tok(TLiteral, "b"),
+				tok(TAnd, "&&"),
+				tok(TNot, "!"),
+				tok(TLiteral, "c"),
+				tok(TColon, ":"),
+				tok(TLiteral, "d"),
+				tok(TOr, "||"),
+				tok(TLiteral, "e"),
+			},
+		},
 		"to_tokenized": {
 			in: `a TO b`,
 			expected: []Token{
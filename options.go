@@ -0,0 +1,153 @@
+package lucene
+
+import (
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// Option configures how a query is parsed
+type Option func(*options)
+
+type options struct {
+	defaultFields []string
+	schema        expr.Schema
+	limits        limits
+}
+
+// limits are the complexity limits enforced while parsing. A zero value means there is no limit.
+type limits struct {
+	maxLength          int
+	maxDepth           int
+	maxClauses         int
+	maxListSize        int
+	noLeadingWildcards bool
+	noRegexps          bool
+}
+
+// WithMaxLength rejects queries longer than n bytes before they are parsed.
+func WithMaxLength(n int) Option {
+	return func(o *options) {
+		o.limits.maxLength = n
+	}
+}
+
+// WithMaxDepth rejects queries nested more than n levels deep. Each parenthesized group and each
+// modifier like NOT, +, -, ^ or ~ wrapping an expression adds a level, so -(a OR b^2) is 3 levels deep.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.limits.maxDepth = n
+	}
+}
+
+// WithMaxClauses rejects queries with more than n boolean clauses. Every AND and OR, implicit or
+// explicit, adds a clause to the query.
+func WithMaxClauses(n int) Option {
+	return func(o *options) {
+		o.limits.maxClauses = n
+	}
+}
+
+// WithMaxListSize rejects queries with an IN list (e.g. a:(b OR c OR d)) of more than n values.
+func WithMaxListSize(n int) Option {
+	return func(o *options) {
+		o.limits.maxListSize = n
+	}
+}
+
+// WithoutLeadingWildcards rejects terms that start with a wildcard (e.g. *foo) since they can't use an
+// index. A lone * is still allowed since it is used for open ended ranges.
+func WithoutLeadingWildcards() Option {
+	return func(o *options) {
+		o.limits.noLeadingWildcards = true
+	}
+}
+
+// WithoutRegexps rejects regular expression terms.
+func WithoutRegexps() Option {
+	return func(o *options) {
+		o.limits.noRegexps = true
+	}
+}
+
+// WithDefaultField sets the field that bare terms (terms without a field like `apple` or `"honey crisp"`)
+// are compared against. If multiple fields are given bare terms are expanded to an OR across all of them.
+// This matches how a default field works in lucene's QueryParser.
+func WithDefaultField(fields ...string) Option {
+	return func(o *options) {
+		o.defaultFields = append(o.defaultFields, fields...)
+	}
+}
+
+// WithSchema checks the values in the query against the types of their fields. Queries that compare a
+// field against a value of the wrong type (e.g. age:abc for an int field) are rejected with an
+// *expr.TypeError and values that can be converted (e.g. "42" for an int field) are coerced.
+func WithSchema(schema expr.Schema) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// expandDefaultFields replaces every bare term in the expression with a comparison against
+// the default fields. Each comparison gets its own copy of the term from clone so a schema can
+// coerce it to the type of that field.
+func expandDefaultFields(e *expr.Expression, fields []string, clone func(*expr.Expression) *expr.Expression) *expr.Expression {
+	if len(fields) == 0 || e == nil {
+		return e
+	}
+
+	if isBareTerm(e) {
+		return defaultFieldExpr(e, fields, clone, func(eq *expr.Expression) *expr.Expression { return eq })
+	}
+
+	sub, _ := e.Left.(*expr.Expression)
+	switch {
+	case e.Op == expr.Fuzzy && sub != nil && isBareTerm(sub):
+		// keep the fuzzy distance on each comparison so it reads the same as field:term~N
+		distance := e.FuzzyDistance()
+		return defaultFieldExpr(sub, fields, clone, func(eq *expr.Expression) *expr.Expression {
+			return expr.FUZZY(eq, distance)
+		})
+	case e.Op == expr.Proximity && sub != nil && isBareTerm(sub):
+		// likewise each comparison reads the same as field:"phrase"~N
+		distance := e.ProximityDistance()
+		return defaultFieldExpr(sub, fields, clone, func(eq *expr.Expression) *expr.Expression {
+			return expr.PROXIMITY(eq, distance)
+		})
+	}
+
+	switch e.Op {
+	case expr.And, expr.Or, expr.Not, expr.Must, expr.MustNot, expr.Boost, expr.Fuzzy, expr.Proximity:
+		if sub != nil {
+			e.Left = expandDefaultFields(sub, fields, clone)
+		}
+		if sub, ok := e.Right.(*expr.Expression); ok {
+			e.Right = expandDefaultFields(sub, fields, clone)
+		}
+	}
+	return e
+}
+
+// defaultFieldExpr builds the comparisons of the term against each field and ORs them together
+func defaultFieldExpr(term *expr.Expression, fields []string, clone, wrap func(*expr.Expression) *expr.Expression) *expr.Expression {
+	var out *expr.Expression
+	for _, field := range fields {
+		eq := wrap(expr.Eq(field, clone(term)))
+		if out == nil {
+			out = eq
+			continue
+		}
+		out = expr.OR(out, eq)
+	}
+	return out
+}
+
+func isBareTerm(e *expr.Expression) bool {
+	return e.Op == expr.Literal || e.Op == expr.Wild || e.Op == expr.Regexp
+}
@@ -2,7 +2,6 @@ package lucene
 
 import (
 	"fmt"
-	"reflect"
 	"strconv"
 	"strings"
 
@@ -13,96 +12,175 @@ import (
 
 // Parse will parse using a buffer and the shift reduce algorithm. It scales rather well since
 // it is a one pass algorithm with no backtracking.
-func Parse(input string) (e *expr.Expression, err error) {
+func Parse(input string, opts ...Option) (e *expr.Expression, err error) {
+	o := newOptions(opts)
+	if o.limits.maxLength > 0 && len(input) > o.limits.maxLength {
+		return e, &LimitError{Limit: "length", Max: o.limits.maxLength, Actual: len(input), Offset: o.limits.maxLength}
+	}
+
 	p := &parser{
+		limits:       o.limits,
+		input:        input,
 		lex:          lex.Lex(input),
 		stack:        []any{},
 		nonTerminals: []lex.Token{{Typ: lex.TStart}},
+		phrases:      map[*expr.Expression]bool{},
+		starts:       map[*expr.Expression]int{},
+		levels:       map[*expr.Expression]int{},
 	}
 	ex, err := p.parse()
 	if err != nil {
 		return e, err
 	}
 
-	err = expr.Validate(ex)
+	err = checkListSize(ex, o.limits.maxListSize)
 	if err != nil {
 		return e, err
 	}
 
+	ex = expandDefaultFields(ex, o.defaultFields, p.clone)
+
+	err = expr.Validate(ex, expr.WithSchema(o.schema))
+	if err != nil {
+		return e, p.validationError(ex, err, o.schema)
+	}
+
 	return ex, nil
 }
 
 type parser struct {
+	input        string
 	lex          *lex.Lexer
 	stack        []any
 	nonTerminals []lex.Token
+
+	// phrases are the literals that came from quoted tokens. A ~ after a phrase is a proximity
+	// search rather than a fuzzy search so we need to remember where they came from.
+	phrases map[*expr.Expression]bool
+
+	// starts are the byte offsets in the input where each expression begins so errors found
+	// after parsing can still point at the right place
+	starts map[*expr.Expression]int
+
+	// levels are how deeply each expression is nested, only tracked when there is a max depth
+	levels map[*expr.Expression]int
+
+	// these track the complexity of the query as we go so we can bail out early
+	limits  limits
+	depth   int
+	clauses int
 }
 
 func (p *parser) parse() (e *expr.Expression, err error) {
 	for {
 		next := p.lex.Peek()
+		if next.Typ == lex.TErr {
+			return e, p.lexError(next)
+		}
+
 		if p.shouldAccept(next) {
 			if len(p.stack) != 1 {
-				return e, fmt.Errorf("multiple expression left after parsing: %v", p.stack)
+				return e, newParseError(p.input, next, "", "unexpected %s", found(next))
 			}
 			final, ok := p.stack[0].(*expr.Expression)
 			if !ok {
-				return e, fmt.Errorf(
-					"final parse didn't return an expression: %s [type: %s]",
-					p.stack[0],
-					reflect.TypeOf(final),
-				)
+				return e, newParseError(p.input, next, "a term", "unexpected %s", found(next))
 			}
 			return final, nil
 		}
 
 		if p.shouldShift(next) {
 			tok := p.shift()
+			err = p.checkLimits(tok)
+			if err != nil {
+				return e, err
+			}
+			if tok.Typ == lex.TRParen && p.depth < 0 {
+				return e, newParseError(p.input, tok, "a matching '('", "unexpected %s", found(tok))
+			}
+
 			if lex.IsTerminal(tok) {
 				// if we have a terminal parse it and put it on the stack
 				lit, err := parseLiteral(tok)
 				if err != nil {
 					return e, err
 				}
+				if tok.Typ == lex.TQuoted {
+					p.phrases[lit.(*expr.Expression)] = true
+				}
+				p.starts[lit.(*expr.Expression)] = tok.Pos()
 
-				// we should always check if the current top of the stack is another token
-				// if it isn't then we have an implicit AND we need to inject.
-				if len(p.stack) > 0 {
-					_, isTopToken := p.stack[len(p.stack)-1].(lex.Token)
-					if !isTopToken {
-						implAnd := lex.Token{Typ: lex.TAnd, Val: "AND"}
-						// act as if we just saw an AND and check if we need to reduce the
-						// current token stack first.
-						if !p.shouldShift(implAnd) {
-							err = p.reduce()
-							if err != nil {
-								return e, err
-							}
-						}
-
-						// if we have a literal as the previous parsed thing then
-						// we must be in an implicit AND and should reduce
-						p.stack = append(p.stack, implAnd)
-						p.nonTerminals = append(p.nonTerminals, implAnd)
-					}
+				err = p.injectImplicitAnd(tok)
+				if err != nil {
+					return e, err
 				}
 
 				p.stack = append(p.stack, lit)
 				continue
 			}
+
+			// a prefix operator directly after an expression also starts a new clause
+			if startsClause(tok) {
+				err = p.injectImplicitAnd(tok)
+				if err != nil {
+					return e, err
+				}
+			}
+
 			// otherwise just push the token on the stack
 			p.stack = append(p.stack, tok)
 			p.nonTerminals = append(p.nonTerminals, tok)
 			continue
 		}
 
-		err = p.reduce()
+		err = p.reduce(next)
 		if err != nil {
 			return e, err
 		}
 	}
 }
 
+// injectImplicitAnd checks if the current top of the stack is another token. If it isn't then
+// the next clause is joined to the previous expression with an implicit AND we need to inject.
+func (p *parser) injectImplicitAnd(tok lex.Token) (err error) {
+	if len(p.stack) == 0 {
+		return nil
+	}
+
+	_, isTopToken := p.stack[len(p.stack)-1].(lex.Token)
+	if isTopToken {
+		return nil
+	}
+
+	// the implied AND sits where the clause it joins starts
+	implAnd := lex.NewToken(lex.TAnd, "AND", tok.Pos())
+	err = p.checkLimits(implAnd)
+	if err != nil {
+		return err
+	}
+
+	// act as if we just saw an AND and reduce the current token stack until
+	// the AND can be shifted on.
+	for !p.shouldShift(implAnd) {
+		err = p.reduce(tok)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.stack = append(p.stack, implAnd)
+	p.nonTerminals = append(p.nonTerminals, implAnd)
+	return nil
+}
+
+// startsClause checks if the token is a prefix operator that can only start a new clause
+func startsClause(tok lex.Token) bool {
+	return tok.Typ == lex.TPlus ||
+		tok.Typ == lex.TMinus ||
+		tok.Typ == lex.TNot ||
+		tok.Typ == lex.TLParen
+}
+
 func (p *parser) shift() (tok lex.Token) {
 	return p.lex.Next()
 }
@@ -170,11 +248,20 @@ func (p *parser) shouldAccept(next lex.Token) bool {
 		next.Typ == lex.TEOF
 }
 
-func (p *parser) reduce() (err error) {
+// reduce reduces the top of the stack. The lookahead token is only used to report where
+// the parser got stuck if nothing can be reduced.
+func (p *parser) reduce(lookahead lex.Token) (err error) {
 	top := []any{}
 	for {
 		if len(p.stack) == 0 {
-			return fmt.Errorf("error parsing, no items left to reduce, current state: %v", top)
+			curr := p.nonTerminals[len(p.nonTerminals)-1]
+			return newParseError(
+				p.input,
+				lookahead,
+				expectedAfter[curr.Typ],
+				"unexpected %s",
+				found(lookahead),
+			)
 		}
 
 		// pull the top off the stack
@@ -185,18 +272,220 @@ func (p *parser) reduce() (err error) {
 		top = append([]any{s}, top...)
 
 		// try to reduce with all our reducers
+		start := p.start(top[0])
+		open, isGroup := top[0].(lex.Token)
+		isGroup = isGroup && open.Typ == lex.TLParen
+
 		var reduced bool
 		top, p.nonTerminals, reduced = reduce.Reduce(top, p.nonTerminals)
 
 		// if we consumed some non terminals during the reduce it means we successfully reduced
 		if reduced {
+			for _, item := range top {
+				e, isExpr := item.(*expr.Expression)
+				if !isExpr {
+					continue
+				}
+
+				_, known := p.starts[e]
+				if !known {
+					p.starts[e] = start
+				}
+				if !known || isGroup {
+					err = p.nest(e, isGroup, lookahead)
+					if err != nil {
+						return err
+					}
+				}
+			}
 			// if we successfully reduced re-add it to the top of the stack and return
 			p.stack = append(p.stack, top...)
+			p.promoteProximity()
 			return nil
 		}
 	}
 }
 
+// promoteProximity turns a fuzzy search that was just reduced into a proximity search if
+// it wraps a phrase, either on its own ("foo bar"~2) or on a field (a:"foo bar"~2).
+func (p *parser) promoteProximity() {
+	fuzzy, ok := p.stack[len(p.stack)-1].(*expr.Expression)
+	if !ok || fuzzy.Op != expr.Fuzzy {
+		return
+	}
+
+	sub, ok := fuzzy.Left.(*expr.Expression)
+	if !ok {
+		return
+	}
+
+	phrase := sub
+	if sub.Op == expr.Equals {
+		phrase, _ = sub.Right.(*expr.Expression)
+	}
+	if !p.phrases[phrase] {
+		return
+	}
+
+	proximity := expr.PROXIMITY(sub, fuzzy.FuzzyDistance())
+	p.starts[proximity] = p.starts[fuzzy]
+	p.levels[proximity] = p.levels[fuzzy]
+	p.stack[len(p.stack)-1] = proximity
+}
+
+// nest records how deeply a reduced expression is nested and returns a LimitError once it is nested
+// deeper than the max depth. Parenthesized groups and modifiers like NOT, +, ^ and ~ each add a level.
+// Checking as each expression is reduced stops long chains like a^2^2^2... before they get expensive.
+func (p *parser) nest(e *expr.Expression, isGroup bool, lookahead lex.Token) error {
+	if p.limits.maxDepth <= 0 {
+		return nil
+	}
+
+	level, known := p.levels[e]
+	if !known {
+		for _, child := range expr.Children(e) {
+			if p.levels[child] > level {
+				level = p.levels[child]
+			}
+		}
+		if isModifier(e.Op) {
+			level++
+		}
+	}
+	if isGroup {
+		level++
+	}
+	p.levels[e] = level
+
+	if level > p.limits.maxDepth {
+		return &LimitError{Limit: "depth", Max: p.limits.maxDepth, Actual: level, Offset: lookahead.Pos()}
+	}
+	return nil
+}
+
+// isModifier checks if the operator wraps a single expression to change how it matches
+func isModifier(op expr.Operator) bool {
+	switch op {
+	case expr.Not, expr.Must, expr.MustNot, expr.Boost, expr.Fuzzy, expr.Proximity:
+		return true
+	}
+	return false
+}
+
+// clone copies an expression, keeping track of where the original began in the input
+func (p *parser) clone(e *expr.Expression) *expr.Expression {
+	cp := *e
+	if start, known := p.starts[e]; known {
+		p.starts[&cp] = start
+	}
+	return &cp
+}
+
+// start returns the offset in the input where an item on the stack begins
+func (p *parser) start(item any) int {
+	if tok, isToken := item.(lex.Token); isToken {
+		return tok.Pos()
+	}
+	e, _ := item.(*expr.Expression)
+	return p.offset(e)
+}
+
+// offset returns where the expression begins in the input. Expressions built after parsing, like
+// the ones for default fields, don't have a start of their own so the earliest one inside is used.
+func (p *parser) offset(e *expr.Expression) int {
+	offset, known := p.starts[e]
+	if known {
+		return offset
+	}
+
+	offset = len(p.input)
+	expr.Inspect(e, func(sub *expr.Expression) bool {
+		if start, known := p.starts[sub]; known && start < offset {
+			offset = start
+		}
+		return true
+	})
+	return offset
+}
+
+// validationError converts an error from validating the parsed expression into a parse error
+// pointing at the innermost expression that fails validation on its own
+func (p *parser) validationError(e *expr.Expression, err error, schema expr.Schema) *ParseError {
+	culprit := e
+	expr.Walk(e, nil, func(c expr.Cursor) bool {
+		if expr.Validate(c.Expr, expr.WithSchema(schema)) != nil {
+			culprit = c.Expr
+			return false
+		}
+		return true
+	})
+
+	perr := newParseErrorAt(p.input, p.offset(culprit), "", "", err.Error())
+	perr.Err = err
+	return perr
+}
+
+// checkLimits tracks the complexity of the query as each token is shifted and returns a LimitError
+// as soon as the query goes over one of the limits.
+func (p *parser) checkLimits(tok lex.Token) error {
+	switch tok.Typ {
+	case lex.TLParen:
+		p.depth++
+		if p.limits.maxDepth > 0 && p.depth > p.limits.maxDepth {
+			return &LimitError{Limit: "depth", Max: p.limits.maxDepth, Actual: p.depth, Offset: tok.Pos()}
+		}
+	case lex.TRParen:
+		p.depth--
+	case lex.TAnd, lex.TOr:
+		p.clauses++
+		// n boolean operators join n+1 clauses
+		if p.limits.maxClauses > 0 && p.clauses+1 > p.limits.maxClauses {
+			return &LimitError{Limit: "clauses", Max: p.limits.maxClauses, Actual: p.clauses + 1, Offset: tok.Pos()}
+		}
+	case lex.TLiteral:
+		if p.limits.noLeadingWildcards && tok.Val != "*" && strings.ContainsAny(tok.Val[:1], "*?") {
+			return &LimitError{Limit: "leading wildcard", Offset: tok.Pos()}
+		}
+	case lex.TRegexp:
+		if p.limits.noRegexps {
+			return &LimitError{Limit: "regexp", Offset: tok.Pos()}
+		}
+	}
+	return nil
+}
+
+// checkListSize makes sure none of the IN lists in the expression are longer than max
+func checkListSize(e *expr.Expression, max int) (err error) {
+	if max <= 0 {
+		return nil
+	}
+
+	expr.Inspect(e, func(sub *expr.Expression) bool {
+		if err != nil {
+			return false
+		}
+
+		if sub.Op == expr.List {
+			vals, _ := sub.Left.([]*expr.Expression)
+			if len(vals) > max {
+				err = &LimitError{Limit: "list size", Max: max, Actual: len(vals), Offset: -1}
+			}
+			return false
+		}
+		return true
+	})
+	return err
+}
+
+// lexError converts an error token from the lexer into a parse error
+func (p *parser) lexError(tok lex.Token) *ParseError {
+	expected := ""
+	if strings.HasPrefix(tok.Val, "unterminated") && tok.Pos() < len(p.input) {
+		expected = fmt.Sprintf("closing %c", p.input[tok.Pos()])
+	}
+	return newParseError(p.input, tok, expected, "%s", tok.Val)
+}
+
 func parseLiteral(token lex.Token) (e any, err error) {
 	// if it is a quote then remove escape
 	if token.Typ == lex.TQuoted {
@@ -220,6 +509,12 @@ func parseLiteral(token lex.Token) (e any, err error) {
 		return expr.Lit(fval), nil
 	}
 
+	// attempt to parse it as a date or date math. A bare now stays a string so it can still be searched for
+	date, err := expr.ParseDate(token.Val)
+	if err == nil && token.Val != "now" {
+		return expr.Lit(date), nil
+	}
+
 	// if it contains unescaped wildcards then it is a wildcard string
 	if strings.ContainsAny(token.Val, "*?") {
 		return expr.WILD(token.Val), nil
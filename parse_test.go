@@ -2,8 +2,10 @@ package lucene
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
@@ -74,6 +76,60 @@ func TestParseLucene(t *testing.T) {
 			input: "a:{foo TO bar}",
 			want:  expr.Rang("a", "foo", "bar", false),
 		},
+		"date_comparison": {
+			input: "updated:>2024-01-01",
+			want:  expr.GREATER("updated", "2024-01-01"),
+		},
+		"date_time_with_offset": {
+			input: "a:2024-01-01T10:00:00+02:00",
+			want:  expr.Eq("a", "2024-01-01T10:00:00+02:00"),
+		},
+		"date_math_range": {
+			input: "created_at:[now-7d/d TO now]",
+			want:  expr.Rang("created_at", "now-7d/d", "now", true),
+		},
+		"anchored_date_math": {
+			input: "a:2024-01-31||+1M/M",
+			want:  expr.Eq("a", "2024-01-31||+1M/M"),
+		},
+		"anchored_date_math_range": {
+			input: "a:[2024-01-01 TO 2024-01-31||+1M]",
+			want:  expr.Rang("a", "2024-01-01", "2024-01-31||+1M", true),
+		},
+		"or_after_a_date": {
+			input: "a:2024-01-31||b:c",
+			want:  expr.OR(expr.Eq("a", "2024-01-31"), expr.Eq("b", "c")),
+		},
+		"bare_now_is_a_string": {
+			input: "a:now",
+			want:  expr.Eq("a", expr.Lit("now")),
+		},
+		"exists": {
+			input: "_exists_:a",
+			want:  expr.EXISTS("a"),
+		},
+		"exists_wildcard": {
+			input: "a:* AND b:c*",
+			want: expr.AND(
+				expr.EXISTS("a"),
+				expr.LIKE("b", expr.WILD("c*")),
+			),
+		},
+		"not_exists": {
+			input: "NOT _exists_:a OR -b:*",
+			want: expr.OR(
+				expr.NOT(expr.EXISTS("a")),
+				expr.MUSTNOT(expr.EXISTS("b")),
+			),
+		},
+		"range_inclusive_min_exclusive_max": {
+			input: "age:[18 TO 65}",
+			want:  expr.RangBounds("age", 18, 65, true, false),
+		},
+		"range_exclusive_min_inclusive_max": {
+			input: "a:{foo TO *]",
+			want:  expr.RangBounds("a", "foo", expr.WILD("*"), false, true),
+		},
 		"basic_fuzzy": {
 			input: "b AND a~",
 			want:  expr.AND("b", expr.FUZZY("a", 1)),
@@ -154,6 +210,44 @@ func TestParseLucene(t *testing.T) {
 			input: "NOT b",
 			want:  expr.NOT("b"),
 		},
+		"symbolic_and": {
+			input: "a && b",
+			want:  expr.AND("a", "b"),
+		},
+		"symbolic_or": {
+			input: "a:foo || b:bar",
+			want: expr.OR(
+				expr.Eq("a", "foo"),
+				expr.Eq("b", "bar"),
+			),
+		},
+		"symbolic_not": {
+			input: "!b",
+			want:  expr.NOT("b"),
+		},
+		"symbolic_precedence": {
+			input: "a || b && !c || d",
+			want: expr.OR(
+				expr.OR(
+					"a",
+					expr.AND("b", expr.NOT("c")),
+				),
+				"d",
+			),
+		},
+		"symbolic_mixed_with_keywords": {
+			input: "(a:b && c:d) OR NOT e:f || !g",
+			want: expr.OR(
+				expr.OR(
+					expr.AND(
+						expr.Eq("a", "b"),
+						expr.Eq("c", "d"),
+					),
+					expr.NOT(expr.Eq("e", "f")),
+				),
+				expr.NOT("g"),
+			),
+		},
 		"nested_not": {
 			input: "a:foo OR NOT b:bar",
 			want: expr.OR(
@@ -182,6 +276,57 @@ func TestParseLucene(t *testing.T) {
 				),
 			),
 		},
+		"implicit_and_prefix_operators": {
+			input: "+a -b NOT c",
+			want: expr.AND(
+				expr.AND(
+					expr.MUST("a"),
+					expr.MUSTNOT("b"),
+				),
+				expr.NOT("c"),
+			),
+		},
+		"field_grouping_must": {
+			input: `title:(+return +"pink panther")`,
+			want: expr.AND(
+				expr.MUST(expr.Eq("title", "return")),
+				expr.MUST(expr.Eq("title", "pink panther")),
+			),
+		},
+		"field_grouping_mixed_operators": {
+			input: "title:(foo OR bar AND NOT baz)",
+			want: expr.OR(
+				expr.Eq("title", "foo"),
+				expr.AND(
+					expr.Eq("title", "bar"),
+					expr.NOT(expr.Eq("title", "baz")),
+				),
+			),
+		},
+		"field_grouping_nested_in": {
+			input: "title:((foo OR bar) AND -baz*)",
+			want: expr.AND(
+				expr.IN("title", expr.LIST(expr.Lit("foo"), expr.Lit("bar"))),
+				expr.MUSTNOT(expr.LIKE("title", expr.WILD("baz*"))),
+			),
+		},
+		"field_grouping_modifiers": {
+			input: `title:(foo^2 OR bar~1 OR "pink panther"~3)`,
+			want: expr.OR(
+				expr.OR(
+					expr.BOOST(expr.Eq("title", "foo"), 2),
+					expr.FUZZY(expr.Eq("title", "bar"), 1),
+				),
+				expr.PROXIMITY(expr.Eq("title", "pink panther"), 3),
+			),
+		},
+		"field_grouping_keeps_inner_field": {
+			input: "title:(foo OR body:bar)",
+			want: expr.OR(
+				expr.Eq("title", "foo"),
+				expr.Eq("body", "bar"),
+			),
+		},
 		"basic_must": {
 			input: "+a:b",
 			want: expr.MUST(
@@ -339,13 +484,24 @@ func TestParseLucene(t *testing.T) {
 				expr.Eq("a", "b"),
 			),
 		},
-		"fuzzy_quoted_literal": {
+		"proximity_quoted_literal": {
 			input: `"foo bar"~4 AND a:b`,
 			want: expr.AND(
-				expr.FUZZY(expr.Lit("foo bar"), 4),
+				expr.PROXIMITY(expr.Lit("foo bar"), 4),
 				expr.Eq("a", "b"),
 			),
 		},
+		"proximity_quoted_literal_default_distance": {
+			input: `"foo bar"~`,
+			want:  expr.PROXIMITY(expr.Lit("foo bar")),
+		},
+		"proximity_field": {
+			input: `a:"foo bar"~3 OR b:baz~2`,
+			want: expr.OR(
+				expr.PROXIMITY(expr.Eq("a", "foo bar"), 3),
+				expr.FUZZY(expr.Eq("b", "baz"), 2),
+			),
+		},
 		"fuzzy_sub_expression": {
 			input: "(title:foo OR title:bar)~2 AND (body:foo OR body:bar)",
 			want: expr.AND(
@@ -502,6 +658,30 @@ func TestParseLucene(t *testing.T) {
 				),
 			),
 		},
+		"must_not_number": {
+			input: "- 5",
+			want:  expr.MUSTNOT(5),
+		},
+		"must_not_grouped_number": {
+			input: "-(5)",
+			want:  expr.MUSTNOT(5),
+		},
+		"must_not_negative_number": {
+			input: "-(-5)",
+			want:  expr.MUSTNOT(-5),
+		},
+		"must_not_field_starting_with_number": {
+			input: "-(5a:b)",
+			want:  expr.MUSTNOT(expr.Eq("5a", "b")),
+		},
+		"escaped_star_in_field": {
+			input: `a\*b:c`,
+			want:  expr.Eq(`a\*b`, "c"),
+		},
+		"escaped_question_mark_in_field": {
+			input: `a\?b:c`,
+			want:  expr.Eq(`a\?b`, "c"),
+		},
 	}
 
 	for name, tc := range tcs {
@@ -532,6 +712,374 @@ func TestParseLucene(t *testing.T) {
 				// require.Equal(t, tc.want, gotSerialized)
 				t.Fatalf(errTemplate, "roundtrip serialization is not stable", tc.want, gotSerialized)
 			}
+
+			formatted := expr.Format(got)
+			reparsed, err := Parse(formatted)
+			if err != nil {
+				t.Fatalf("wanted no error parsing formatted query [%s], got: %v", formatted, err)
+			}
+			if !reflect.DeepEqual(got, reparsed) {
+				t.Fatalf(errTemplate, fmt.Sprintf("formatted query [%s] doesn't parse back the same", formatted), got, reparsed)
+			}
+
+			// a narrow width breaks everything it can over multiple lines
+			pretty := expr.Pretty(got, 10)
+			reparsed, err = Parse(pretty)
+			if err != nil {
+				t.Fatalf("wanted no error parsing pretty printed query [%s], got: %v", pretty, err)
+			}
+			if !reflect.DeepEqual(got, reparsed) {
+				t.Fatalf(errTemplate, fmt.Sprintf("pretty printed query [%s] doesn't parse back the same", pretty), got, reparsed)
+			}
+		})
+	}
+}
+
+func TestParseDefaultField(t *testing.T) {
+	type tc struct {
+		input  string
+		fields []string
+		want   *expr.Expression
+	}
+
+	tcs := map[string]tc{
+		"single_literal": {
+			input:  "a",
+			fields: []string{"body"},
+			want:   expr.Eq("body", "a"),
+		},
+		"quoted_literal": {
+			input:  `"honey crisp"`,
+			fields: []string{"body"},
+			want:   expr.Eq("body", "honey crisp"),
+		},
+		"wildcard_literal": {
+			input:  "a*",
+			fields: []string{"body"},
+			want:   expr.LIKE("body", "a*"),
+		},
+		"fields_are_untouched": {
+			input:  "a:b c",
+			fields: []string{"body"},
+			want: expr.AND(
+				expr.Eq("a", "b"),
+				expr.Eq("body", "c"),
+			),
+		},
+		"nested_literals": {
+			input:  "NOT a OR (+b AND c^2)",
+			fields: []string{"body"},
+			want: expr.OR(
+				expr.NOT(expr.Eq("body", "a")),
+				expr.AND(
+					expr.MUST(expr.Eq("body", "b")),
+					expr.BOOST(expr.Eq("body", "c"), 2),
+				),
+			),
+		},
+		"fuzzy_literal": {
+			input:  "a~2",
+			fields: []string{"body"},
+			want:   expr.FUZZY(expr.Eq("body", "a"), 2),
+		},
+		"proximity_phrase": {
+			input:  `"honey crisp"~2`,
+			fields: []string{"title", "body"},
+			want: expr.OR(
+				expr.PROXIMITY(expr.Eq("title", "honey crisp"), 2),
+				expr.PROXIMITY(expr.Eq("body", "honey crisp"), 2),
+			),
+		},
+		"multiple_fields": {
+			input:  "a",
+			fields: []string{"title", "body", "tags"},
+			want: expr.OR(
+				expr.OR(
+					expr.Eq("title", "a"),
+					expr.Eq("body", "a"),
+				),
+				expr.Eq("tags", "a"),
+			),
+		},
+		"multiple_fields_fuzzy": {
+			input:  "a~",
+			fields: []string{"title", "body"},
+			want: expr.OR(
+				expr.FUZZY(expr.Eq("title", "a"), 1),
+				expr.FUZZY(expr.Eq("body", "a"), 1),
+			),
+		},
+		"no_fields": {
+			input: "a",
+			want:  expr.Lit("a"),
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(tc.input, WithDefaultField(tc.fields...))
+			if err != nil {
+				t.Fatalf("wanted no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf(errTemplate, "parsed expression doesn't match", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	type tc struct {
+		input  string
+		fields []string // default fields for bare terms
+		want   *expr.Expression
+		err    string
+	}
+
+	schema := expr.Schema{
+		"age":     expr.TypeInt,
+		"price":   expr.TypeFloat,
+		"name":    expr.TypeString,
+		"active":  expr.TypeBool,
+		"created": expr.TypeDate,
+		"color":   expr.Enum("red", "green"),
+	}
+
+	tcs := map[string]tc{
+		"coerce_quoted_int": {
+			input: `age:"42"`,
+			want:  expr.Eq("age", 42),
+		},
+		"coerce_int_to_float": {
+			input: "price:[1 TO 2.5}",
+			want:  expr.RangBounds("price", 1.0, 2.5, true, false),
+		},
+		"coerce_number_to_string": {
+			input: "name:42",
+			want:  expr.Eq("name", "42"),
+		},
+		"default_fields_coerce_separately": {
+			input:  `"42"`,
+			fields: []string{"name", "age"},
+			want:   expr.OR(expr.Eq("name", "42"), expr.Eq("age", 42)),
+		},
+		"default_fields_coerce_separately_reversed": {
+			input:  `"42"`,
+			fields: []string{"age", "name"},
+			want:   expr.OR(expr.Eq("age", 42), expr.Eq("name", "42")),
+		},
+		"string_range": {
+			input: "name:[a TO m}",
+			want:  expr.RangBounds("name", "a", "m", true, false),
+		},
+		"coerce_bool": {
+			input: "active:true",
+			want:  expr.Eq("active", true),
+		},
+		"coerce_now_to_date": {
+			input: "created:now",
+			want:  expr.Eq("created", expr.Lit(mustDate(t, "now"))),
+		},
+		"coerce_list": {
+			input: "age:(1 OR 2)",
+			want:  expr.IN("age", expr.LIST(expr.Lit(1), expr.Lit(2))),
+		},
+		"enum_value": {
+			input: "color:red",
+			want:  expr.Eq("color", "red"),
+		},
+		"unknown_fields_are_not_checked": {
+			input: "other:abc",
+			want:  expr.Eq("other", "abc"),
+		},
+		"int_mismatch": {
+			input: "age:abc",
+			err:   "field [age] expects a value of type int, got [abc]",
+		},
+		"int_mismatch_in_range": {
+			input: "a:b AND age:[1 TO 5.5]",
+			err:   "field [age] expects a value of type int, got [5.5]",
+		},
+		"date_mismatch": {
+			input: "created:yesterday",
+			err:   "field [created] expects a value of type date, got [yesterday]",
+		},
+		"enum_mismatch": {
+			input: "color:blue",
+			err:   "field [color] expects a value of type enum(red, green), got [blue]",
+		},
+		"wildcard_on_int": {
+			input: "age:4*",
+			err:   "field [age] of type int does not support LIKE",
+		},
+		"range_on_bool": {
+			input: "active:[false TO true]",
+			err:   "field [active] of type bool does not support RANGE",
+		},
+		"number_range_on_string": {
+			input: "name:[1 TO 5]",
+			err:   "field [name] expects a value of type string, got [1]",
+		},
+		"number_comparison_on_string": {
+			input: "name:>5",
+			err:   "field [name] expects a value of type string, got [5]",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option{WithSchema(schema)}
+			if len(tc.fields) > 0 {
+				opts = append(opts, WithDefaultField(tc.fields...))
+			}
+
+			got, err := Parse(tc.input, opts...)
+			if tc.err != "" {
+				var terr *expr.TypeError
+				if !errors.As(err, &terr) {
+					t.Fatalf("expected a TypeError but got %T: %v", err, err)
+				}
+				if terr.Error() != tc.err {
+					t.Fatalf(errTemplate, "error doesn't match", tc.err, terr.Error())
+				}
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("expected the TypeError to be wrapped in a ParseError but got %T: %v", err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("wanted no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf(errTemplate, "parsed expression doesn't match", tc.want, got)
+			}
+		})
+	}
+}
+
+func mustDate(t *testing.T, s string) expr.Date {
+	d, err := expr.ParseDate(s)
+	if err != nil {
+		t.Fatalf("invalid date %s: %v", s, err)
+	}
+	return d
+}
+
+func TestParseLimits(t *testing.T) {
+	type tc struct {
+		input  string
+		opts   []Option
+		limit  string
+		offset int // only checked when it is above 0
+	}
+
+	tcs := map[string]tc{
+		"within_all_limits": {
+			input: "(a:b OR c:d) AND e:(f OR g) AND h:[1 TO *] AND i:j*",
+			opts: []Option{
+				WithMaxLength(100),
+				WithMaxDepth(1),
+				WithMaxClauses(6),
+				WithMaxListSize(2),
+				WithoutLeadingWildcards(),
+				WithoutRegexps(),
+			},
+		},
+		"too_long": {
+			input: "a:b AND c:d",
+			opts:  []Option{WithMaxLength(5)},
+			limit: "length",
+		},
+		"too_deep": {
+			input: "a:b AND (c:d OR (e:f AND (g:h)))",
+			opts:  []Option{WithMaxDepth(2)},
+			limit: "depth",
+		},
+		"boost_chain_too_deep": {
+			input: "a:b" + strings.Repeat("^2", 1000),
+			opts:  []Option{WithMaxDepth(5)},
+			limit: "depth",
+		},
+		"modifiers_too_deep": {
+			input: "-(a OR b^2)",
+			opts:  []Option{WithMaxDepth(2)},
+			limit: "depth",
+		},
+		"modifiers_within_depth": {
+			input: "-(a OR b^2)",
+			opts:  []Option{WithMaxDepth(3)},
+		},
+		"too_many_clauses": {
+			input: "a OR b OR c OR d",
+			opts:  []Option{WithMaxClauses(3)},
+			limit: "clauses",
+		},
+		"too_many_implicit_clauses": {
+			input: "a b c d",
+			opts:  []Option{WithMaxClauses(3)},
+			limit: "clauses",
+		},
+		"implicit_clause_offset": {
+			input:  "a:b c:d e:f",
+			opts:   []Option{WithMaxClauses(2)},
+			limit:  "clauses",
+			offset: 8,
+		},
+		"list_too_big": {
+			input: "a:(b OR c OR d)",
+			opts:  []Option{WithMaxListSize(2)},
+			limit: "list size",
+		},
+		"leading_wildcard": {
+			input: "a:*foo",
+			opts:  []Option{WithoutLeadingWildcards()},
+			limit: "leading wildcard",
+		},
+		"leading_single_wildcard": {
+			input: "a:?oo",
+			opts:  []Option{WithoutLeadingWildcards()},
+			limit: "leading wildcard",
+		},
+		"lone_question_mark": {
+			input:  "a:?",
+			opts:   []Option{WithoutLeadingWildcards()},
+			limit:  "leading wildcard",
+			offset: 2,
+		},
+		"lone_star_is_allowed": {
+			input: "a:[1 TO *] AND b:*",
+			opts:  []Option{WithoutLeadingWildcards()},
+		},
+		"regexp": {
+			input: "a:/fo+/",
+			opts:  []Option{WithoutRegexps()},
+			limit: "regexp",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.input, tc.opts...)
+			if tc.limit == "" {
+				if err != nil {
+					t.Fatalf("wanted no error, got: %v", err)
+				}
+				return
+			}
+
+			var lerr *LimitError
+			if !errors.As(err, &lerr) {
+				t.Fatalf("expected a LimitError but got %T: %v", err, err)
+			}
+
+			if lerr.Limit != tc.limit {
+				t.Fatalf(errTemplate, "exceeded limit doesn't match", tc.limit, lerr.Limit)
+			}
+			if tc.offset > 0 && lerr.Offset != tc.offset {
+				t.Fatalf(errTemplate, "limit offset doesn't match", tc.offset, lerr.Offset)
+			}
 		})
 	}
 }
@@ -638,6 +1186,148 @@ func TestParseFailure(t *testing.T) {
 	}
 }
 
+func TestParseErrorPosition(t *testing.T) {
+	type tc struct {
+		input   string
+		want    ParseError
+		wantMsg string // only compared when set
+	}
+
+	tcs := map[string]tc{
+		"unterminated_quote": {
+			input: `a:"abc`,
+			want: ParseError{
+				Offset:   2,
+				Line:     1,
+				Column:   3,
+				Token:    `"abc`,
+				Expected: `closing "`,
+			},
+		},
+		"unterminated_regexp_on_second_line": {
+			input: "a:b\nAND c:/x",
+			want: ParseError{
+				Offset:   10,
+				Line:     2,
+				Column:   7,
+				Token:    "/x",
+				Expected: "closing /",
+			},
+		},
+		"invalid_token": {
+			input: "a:b AND c:%",
+			want: ParseError{
+				Offset: 10,
+				Line:   1,
+				Column: 11,
+				Token:  "%",
+			},
+		},
+		"single_ampersand": {
+			input: "a:b & c:d",
+			want: ParseError{
+				Offset: 4,
+				Line:   1,
+				Column: 5,
+				Token:  "& c:d",
+			},
+		},
+		"and_without_rhs": {
+			input: "a AND",
+			want: ParseError{
+				Offset:   5,
+				Line:     1,
+				Column:   6,
+				Expected: "a term",
+			},
+		},
+		"equal_without_rhs": {
+			input: "a = ",
+			want: ParseError{
+				Offset:   4,
+				Line:     1,
+				Column:   5,
+				Expected: "a value",
+			},
+		},
+		"unpaired_paren": {
+			input: "(a AND b",
+			want: ParseError{
+				Offset:   8,
+				Line:     1,
+				Column:   9,
+				Expected: "')'",
+			},
+			wantMsg: "unexpected end of input",
+		},
+		"empty_group": {
+			input: "() = ()",
+			want: ParseError{
+				Offset:   3,
+				Line:     1,
+				Column:   4,
+				Token:    "=",
+				Expected: "a matching '('",
+			},
+		},
+		"unmatched_closing_paren": {
+			input: "a:b)",
+			want: ParseError{
+				Offset:   3,
+				Line:     1,
+				Column:   4,
+				Token:    ")",
+				Expected: "a matching '('",
+			},
+		},
+		"validation_error": {
+			input: "x:y AND a:b:c",
+			want: ParseError{
+				Offset: 8,
+				Line:   1,
+				Column: 9,
+			},
+			wantMsg: "EQUALS validation: left value must be a literal expression",
+		},
+		"multibyte_column": {
+			input: `ü:"abc`,
+			want: ParseError{
+				Offset:   3,
+				Line:     1,
+				Column:   3,
+				Token:    `"abc`,
+				Expected: `closing "`,
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.input)
+			if err == nil {
+				t.Fatalf("expected error but did not get one")
+			}
+
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected a ParseError but got %T: %s", err, err)
+			}
+
+			if tc.wantMsg != "" && perr.Msg != tc.wantMsg {
+				t.Fatalf(errTemplate, "message doesn't match", tc.wantMsg, perr.Msg)
+			}
+
+			// the message is free form so only compare the position information
+			got := *perr
+			got.Msg = ""
+			got.Err = nil
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf(errTemplate, "parse error doesn't match", tc.want, got)
+			}
+		})
+	}
+}
+
 func FuzzParse(f *testing.F) {
 	tcs := []string{
 		"A:B AND C:D",
@@ -3,6 +3,7 @@ package driver
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
 )
@@ -29,19 +30,87 @@ var Shared = map[expr.Operator]RenderFN{
 	expr.LessEq:    lessEq,
 	expr.In:        inFn,
 	expr.List:      list,
+	expr.Exists:    exists,
 }
 
 // Base is the base driver that is embedded in each driver
 type Base struct {
 	renderFNs map[expr.Operator]RenderFN
+
+	// quoteColumn quotes column names for the flavor of sql. If it is not set
+	// columns with spaces are wrapped in double quotes.
+	quoteColumn func(column string) string
+
+	// these are only used when rendering parameterized queries with RenderParam
+	placeholder PlaceholderFN
+	matchFNs    map[expr.Operator]MatchFN
+
+	// columns maps the field names in a query to the sql they render as. If it is set
+	// any field not in the map is rejected.
+	columns map[string]string
+
+	// now is the clock relative dates like now-7d are resolved against. It defaults to time.Now.
+	now func() time.Time
+
+	// timestamp renders a resolved date for the flavor of sql. If it is not set dates are
+	// rendered as quoted RFC 3339 strings.
+	timestamp func(t time.Time) string
+}
+
+// Option configures a driver
+type Option func(*Base)
+
+// WithColumnMap maps the public field names that can be used in a query to the sql expressions they
+// render as, for example `author` to `users.display_name`. Fields that are not in the map are rejected
+// with an UnknownFieldError so users can't reference arbitrary columns. The mongo driver maps the
+// fields to document fields the same way.
+func WithColumnMap(columns map[string]string) Option {
+	return func(b *Base) {
+		b.columns = columns
+	}
+}
+
+// WithClock sets the clock that relative dates like now-7d/d are resolved against when a query
+// is rendered. This is mostly useful for tests.
+func WithClock(now func() time.Time) Option {
+	return func(b *Base) {
+		b.now = now
+	}
+}
+
+// UnknownFieldError is returned when a query references a field that is not in the driver's column map
+type UnknownFieldError struct {
+	Field string
+}
+
+// Error renders the unknown field error
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field [%s]", e.Field)
 }
 
 // Render will render the expression based on the renderFNs provided by the driver.
 func (b Base) Render(e *expr.Expression) (s string, err error) {
+	return b.render(expr.ResolveDates(e, b.clock()))
+}
+
+func (b Base) render(e *expr.Expression) (s string, err error) {
 	if e == nil {
 		return "", nil
 	}
 
+	// ranges over dates are rendered as comparisons so each bound can be a timestamp
+	if e.Op == expr.Range && hasTimeBound(e) {
+		return b.compareRange(e, b.serialize)
+	}
+
+	if field, ok := missingField(e); ok {
+		left, err := b.serialize(field)
+		if err != nil {
+			return s, err
+		}
+		return isNull(left, "")
+	}
+
 	left, err := b.serialize(e.Left)
 	if err != nil {
 		return s, err
@@ -67,11 +136,11 @@ func (b Base) serialize(in any) (s string, err error) {
 
 	switch v := in.(type) {
 	case *expr.Expression:
-		return b.Render(v)
+		return b.render(v)
 	case []*expr.Expression:
 		strs := []string{}
 		for _, e := range v {
-			s, err = b.Render(e)
+			s, err = b.render(e)
 			if err != nil {
 				return s, err
 			}
@@ -79,20 +148,184 @@ func (b Base) serialize(in any) (s string, err error) {
 		}
 		return strings.Join(strs, ", "), nil
 	case *expr.RangeBoundary:
-		if v.Inclusive {
-			return fmt.Sprintf("[%s, %s]", v.Min, v.Max), nil
+		open, closed := "(", ")"
+		if v.MinInclusive {
+			open = "["
+		}
+		if v.MaxInclusive {
+			closed = "]"
 		}
-		return fmt.Sprintf("(%s, %s)", v.Min, v.Max), nil
+		return fmt.Sprintf("%s%s, %s%s", open, v.Min, v.Max, closed), nil
 
 	case expr.Column:
+		if b.columns != nil {
+			mapped, found := b.columns[string(v)]
+			if !found {
+				return s, &UnknownFieldError{Field: string(v)}
+			}
+			return mapped, nil
+		}
+		if b.quoteColumn != nil {
+			return b.quoteColumn(string(v)), nil
+		}
 		if strings.Contains(string(v), " ") {
 			sv := fmt.Sprintf(`"%s"`, string(v))
 			return sv, nil
 		}
 		return fmt.Sprintf("%s", v), nil
+	case time.Time:
+		if b.timestamp != nil {
+			return b.timestamp(v), nil
+		}
+		return fmt.Sprintf("'%s'", v.Format(time.RFC3339Nano)), nil
 	case string:
 		return fmt.Sprintf("'%s'", v), nil
 	default:
 		return fmt.Sprintf("%v", v), nil
 	}
 }
+
+// flatten collects the operands of a chain of the same operator so a AND b AND c
+// can be rendered as a single clause.
+func flatten(e *expr.Expression, op expr.Operator) (out []*expr.Expression) {
+	if e.Op != op {
+		return []*expr.Expression{e}
+	}
+
+	for _, side := range []any{e.Left, e.Right} {
+		sub := subExpr(side)
+		if sub == nil {
+			continue
+		}
+		out = append(out, flatten(sub, op)...)
+	}
+	return out
+}
+
+// subExpr returns the input as an expression or nil if it isn't one
+func subExpr(in any) *expr.Expression {
+	e, _ := in.(*expr.Expression)
+	return e
+}
+
+// fieldOf returns the name of the field in a literal expression
+func fieldOf(in any) (field string, err error) {
+	val, err := valueOf(in)
+	if err != nil {
+		return field, err
+	}
+	return fmt.Sprintf("%v", val), nil
+}
+
+// valueOf returns the raw value wrapped by a literal expression
+func valueOf(in any) (val any, err error) {
+	e, ok := in.(*expr.Expression)
+	if !ok {
+		return val, fmt.Errorf("expected a literal expression, got %T", in)
+	}
+
+	if e.Op != expr.Literal && e.Op != expr.Wild && e.Op != expr.Regexp {
+		return val, fmt.Errorf("expected a literal expression, got [%s]", e.Op)
+	}
+
+	if col, isCol := e.Left.(expr.Column); isCol {
+		return string(col), nil
+	}
+	return e.Left, nil
+}
+
+// missingField checks if the expression is a negated existence check like NOT _exists_:field. These
+// render as a single IS NULL check instead of negating IS NOT NULL.
+func missingField(e *expr.Expression) (field any, ok bool) {
+	if e.Op != expr.Not && e.Op != expr.MustNot {
+		return nil, false
+	}
+
+	sub, isExpr := e.Left.(*expr.Expression)
+	if !isExpr || sub.Op != expr.Exists {
+		return nil, false
+	}
+	return sub.Left, true
+}
+
+// clock returns the current time from the driver's clock
+func (b Base) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// compareRange renders a range as a pair of comparisons using the driver's comparison render functions.
+// Unbounded sides (*) are dropped entirely.
+func (b Base) compareRange(e *expr.Expression, serialize func(in any) (string, error)) (s string, err error) {
+	boundary, ok := e.Right.(*expr.RangeBoundary)
+	if !ok {
+		return s, fmt.Errorf("RANGE must have a range boundary on the right side, got %T", e.Right)
+	}
+
+	left, err := serialize(e.Left)
+	if err != nil {
+		return s, err
+	}
+
+	minOp, maxOp := expr.Greater, expr.Less
+	if boundary.MinInclusive {
+		minOp = expr.GreaterEq
+	}
+	if boundary.MaxInclusive {
+		maxOp = expr.LessEq
+	}
+
+	clauses := []string{}
+	for _, bound := range []struct {
+		op  expr.Operator
+		val any
+	}{
+		{op: minOp, val: boundary.Min},
+		{op: maxOp, val: boundary.Max},
+	} {
+		if isUnbounded(bound.val) {
+			continue
+		}
+
+		right, err := serialize(bound.val)
+		if err != nil {
+			return s, err
+		}
+
+		fn, ok := b.renderFNs[bound.op]
+		if !ok {
+			return s, fmt.Errorf("unable to render operator [%s]", bound.op)
+		}
+
+		clause, err := fn(left, right)
+		if err != nil {
+			return s, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 0 {
+		return s, fmt.Errorf("range on %s must have at least one bound", left)
+	}
+
+	return strings.Join(clauses, " AND "), nil
+}
+
+// hasTimeBound checks if either side of a range is a resolved date
+func hasTimeBound(e *expr.Expression) bool {
+	boundary, ok := e.Right.(*expr.RangeBoundary)
+	if !ok {
+		return false
+	}
+
+	for _, bound := range []any{boundary.Min, boundary.Max} {
+		if lit, isExpr := bound.(*expr.Expression); isExpr {
+			if _, isTime := lit.Left.(time.Time); isTime {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,348 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// ElasticsearchDriver transforms a parsed lucene expression into Elasticsearch query dsl. The output
+// is also compatible with OpenSearch.
+type ElasticsearchDriver struct{}
+
+// NewElasticsearchDriver creates a new driver that will output a parsed lucene expression as Elasticsearch query dsl.
+func NewElasticsearchDriver() ElasticsearchDriver {
+	return ElasticsearchDriver{}
+}
+
+// Render will render the expression as a json encoded query clause. The output is meant to be used
+// as the value of the "query" key in a search request.
+func (d ElasticsearchDriver) Render(e *expr.Expression) (s string, err error) {
+	q, err := d.RenderQuery(e)
+	if err != nil {
+		return s, err
+	}
+
+	raw, err := json.Marshal(q)
+	if err != nil {
+		return s, err
+	}
+	return string(raw), nil
+}
+
+// RenderQuery will render the expression as a query clause that can be marshalled to json.
+func (d ElasticsearchDriver) RenderQuery(e *expr.Expression) (q map[string]any, err error) {
+	if e == nil {
+		return map[string]any{"match_all": map[string]any{}}, nil
+	}
+
+	switch e.Op {
+	case expr.And, expr.Must, expr.MustNot, expr.Not:
+		return d.boolQuery(e)
+	case expr.Or:
+		should := []any{}
+		for _, clause := range flatten(e, expr.Or) {
+			q, err := d.RenderQuery(clause)
+			if err != nil {
+				return q, err
+			}
+			should = append(should, q)
+		}
+		return map[string]any{"bool": map[string]any{"should": should}}, nil
+	case expr.Equals:
+		return d.equals(e)
+	case expr.Like:
+		return d.like(e)
+	case expr.In:
+		return d.terms(e)
+	case expr.Range:
+		return d.rang(e)
+	case expr.Greater, expr.GreaterEq, expr.Less, expr.LessEq:
+		return d.compare(e)
+	case expr.Fuzzy:
+		return d.fuzzy(e)
+	case expr.Proximity:
+		return d.proximity(e)
+	case expr.Exists:
+		field, err := fieldOf(e.Left)
+		if err != nil {
+			return q, err
+		}
+		return map[string]any{"exists": map[string]any{"field": field}}, nil
+	case expr.Boost:
+		q, err := d.RenderQuery(subExpr(e.Left))
+		if err != nil {
+			return q, err
+		}
+		return withBoost(q, e.BoostPower()), nil
+	case expr.Literal:
+		return d.literal(e, nil)
+	case expr.Wild, expr.Regexp:
+		return map[string]any{"query_string": map[string]any{"query": fmt.Sprintf("%v", e.Left)}}, nil
+	}
+
+	return q, fmt.Errorf("unable to render operator [%s]", e.Op)
+}
+
+// boolQuery collects a chain of AND expressions into a single bool query. MUST clauses are added to
+// must, NOT and MUST_NOT clauses are added to must_not, and range and exists clauses are added to filter
+// since they should not affect the score.
+func (d ElasticsearchDriver) boolQuery(e *expr.Expression) (q map[string]any, err error) {
+	clauses := map[string][]any{}
+	for _, clause := range flatten(e, expr.And) {
+		key := "must"
+		switch clause.Op {
+		case expr.Must:
+			clause = subExpr(clause.Left)
+		case expr.MustNot, expr.Not:
+			key = "must_not"
+			clause = subExpr(clause.Left)
+		case expr.Range, expr.Greater, expr.GreaterEq, expr.Less, expr.LessEq, expr.Exists:
+			key = "filter"
+		}
+
+		rendered, err := d.RenderQuery(clause)
+		if err != nil {
+			return q, err
+		}
+		clauses[key] = append(clauses[key], rendered)
+	}
+
+	b := map[string]any{}
+	for key, vals := range clauses {
+		b[key] = vals
+	}
+	return map[string]any{"bool": b}, nil
+}
+
+func (d ElasticsearchDriver) equals(e *expr.Expression) (q map[string]any, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return q, err
+	}
+
+	right := subExpr(e.Right)
+	if right != nil && right.Op == expr.Fuzzy {
+		return d.fuzzy(expr.FUZZY(expr.Eq(e.Left, right.Left), right.FuzzyDistance()))
+	}
+
+	val, err := valueOf(e.Right)
+	if err != nil {
+		return q, err
+	}
+
+	if s, isStr := val.(string); isStr && strings.ContainsAny(s, " \t\r\n") {
+		return map[string]any{"match_phrase": map[string]any{field: map[string]any{"query": s}}}, nil
+	}
+	return map[string]any{"term": map[string]any{field: map[string]any{"value": val}}}, nil
+}
+
+func (d ElasticsearchDriver) like(e *expr.Expression) (q map[string]any, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return q, err
+	}
+
+	right := subExpr(e.Right)
+	if right == nil {
+		return q, fmt.Errorf("LIKE must have a wildcard or regexp on the right side, got %T", e.Right)
+	}
+
+	pattern := fmt.Sprintf("%v", right.Left)
+	switch right.Op {
+	case expr.Wild:
+		return map[string]any{"wildcard": map[string]any{field: map[string]any{"value": pattern}}}, nil
+	case expr.Regexp:
+		return map[string]any{"regexp": map[string]any{field: map[string]any{"value": stripRegexpDelimiters(pattern)}}}, nil
+	}
+	return q, fmt.Errorf("unable to render LIKE with a [%s] pattern", right.Op)
+}
+
+func (d ElasticsearchDriver) terms(e *expr.Expression) (q map[string]any, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return q, err
+	}
+
+	list := subExpr(e.Right)
+	if list == nil || list.Op != expr.List {
+		return q, fmt.Errorf("IN must have a list on the right side, got %T", e.Right)
+	}
+
+	exprs, ok := list.Left.([]*expr.Expression)
+	if !ok {
+		return q, fmt.Errorf("LIST must contain expressions, got %T", list.Left)
+	}
+
+	vals := []any{}
+	for _, v := range exprs {
+		val, err := valueOf(v)
+		if err != nil {
+			return q, err
+		}
+		vals = append(vals, val)
+	}
+	return map[string]any{"terms": map[string]any{field: vals}}, nil
+}
+
+func (d ElasticsearchDriver) rang(e *expr.Expression) (q map[string]any, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return q, err
+	}
+
+	boundary, ok := e.Right.(*expr.RangeBoundary)
+	if !ok {
+		return q, fmt.Errorf("RANGE must have a range boundary on the right side, got %T", e.Right)
+	}
+
+	minKey, maxKey := "gt", "lt"
+	if boundary.MinInclusive {
+		minKey = "gte"
+	}
+	if boundary.MaxInclusive {
+		maxKey = "lte"
+	}
+
+	params := map[string]any{}
+	if !isUnbounded(boundary.Min) {
+		params[minKey], err = valueOf(boundary.Min)
+		if err != nil {
+			return q, err
+		}
+	}
+	if !isUnbounded(boundary.Max) {
+		params[maxKey], err = valueOf(boundary.Max)
+		if err != nil {
+			return q, err
+		}
+	}
+	return map[string]any{"range": map[string]any{field: params}}, nil
+}
+
+func (d ElasticsearchDriver) compare(e *expr.Expression) (q map[string]any, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return q, err
+	}
+
+	val, err := valueOf(e.Right)
+	if err != nil {
+		return q, err
+	}
+
+	key := map[expr.Operator]string{
+		expr.Greater:   "gt",
+		expr.GreaterEq: "gte",
+		expr.Less:      "lt",
+		expr.LessEq:    "lte",
+	}[e.Op]
+	return map[string]any{"range": map[string]any{field: map[string]any{key: val}}}, nil
+}
+
+// fuzzy renders a fuzzy term on a field as a fuzzy query and a fuzzy literal without a field
+// as a multi_match across the default fields.
+func (d ElasticsearchDriver) fuzzy(e *expr.Expression) (q map[string]any, err error) {
+	sub := subExpr(e.Left)
+	if sub == nil {
+		return q, fmt.Errorf("FUZZY must wrap an expression, got %T", e.Left)
+	}
+
+	switch sub.Op {
+	case expr.Literal:
+		return d.literal(sub, map[string]any{"fuzziness": e.FuzzyDistance()})
+	case expr.Equals:
+		field, err := fieldOf(sub.Left)
+		if err != nil {
+			return q, err
+		}
+		val, err := valueOf(sub.Right)
+		if err != nil {
+			return q, err
+		}
+		return map[string]any{"fuzzy": map[string]any{field: map[string]any{
+			"value":     val,
+			"fuzziness": e.FuzzyDistance(),
+		}}}, nil
+	}
+	return q, fmt.Errorf("unable to render FUZZY over [%s]", sub.Op)
+}
+
+// proximity renders a phrase on a field as a match_phrase query with slop and a phrase without a
+// field as a phrase multi_match across the default fields.
+func (d ElasticsearchDriver) proximity(e *expr.Expression) (q map[string]any, err error) {
+	sub := subExpr(e.Left)
+	if sub == nil {
+		return q, fmt.Errorf("PROXIMITY must wrap an expression, got %T", e.Left)
+	}
+
+	switch sub.Op {
+	case expr.Literal:
+		return d.literal(sub, map[string]any{"type": "phrase", "slop": e.ProximityDistance()})
+	case expr.Equals:
+		field, err := fieldOf(sub.Left)
+		if err != nil {
+			return q, err
+		}
+		val, err := valueOf(sub.Right)
+		if err != nil {
+			return q, err
+		}
+		return map[string]any{"match_phrase": map[string]any{field: map[string]any{
+			"query": val,
+			"slop":  e.ProximityDistance(),
+		}}}, nil
+	}
+	return q, fmt.Errorf("unable to render PROXIMITY over [%s]", sub.Op)
+}
+
+// literal renders a bare term without a field as a multi_match across the default fields.
+func (d ElasticsearchDriver) literal(e *expr.Expression, extra map[string]any) (q map[string]any, err error) {
+	val, err := valueOf(e)
+	if err != nil {
+		return q, err
+	}
+
+	params := map[string]any{"query": val}
+	if s, isStr := val.(string); isStr && strings.ContainsAny(s, " \t\r\n") {
+		params["type"] = "phrase"
+	}
+	for k, v := range extra {
+		params[k] = v
+	}
+	return map[string]any{"multi_match": params}, nil
+}
+
+// fieldQueries are the queries that nest their parameters under the field name.
+var fieldQueries = map[string]bool{
+	"term":         true,
+	"range":        true,
+	"wildcard":     true,
+	"regexp":       true,
+	"fuzzy":        true,
+	"match_phrase": true,
+}
+
+// withBoost adds a boost to a rendered query, placing it next to the field parameters
+// for field level queries and at the top level of the query for everything else.
+func withBoost(q map[string]any, power float64) map[string]any {
+	for typ, raw := range q {
+		inner, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if fieldQueries[typ] {
+			for _, params := range inner {
+				if p, ok := params.(map[string]any); ok {
+					p["boost"] = power
+				}
+			}
+			continue
+		}
+		inner["boost"] = power
+	}
+	return q
+}
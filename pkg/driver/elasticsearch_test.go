@@ -0,0 +1,162 @@
+package driver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+func TestElasticsearchDriver(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		want  string
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input: expr.Eq("a", 5),
+			want:  `{"term": {"a": {"value": 5}}}`,
+		},
+		"phrase_equals": {
+			input: expr.Eq("a", "foo bar"),
+			want:  `{"match_phrase": {"a": {"query": "foo bar"}}}`,
+		},
+		"flattened_and": {
+			input: expr.AND(expr.AND(expr.Eq("a", 5), expr.Eq("b", "foo")), expr.Eq("c", "bar")),
+			want: `{"bool": {"must": [
+				{"term": {"a": {"value": 5}}},
+				{"term": {"b": {"value": "foo"}}},
+				{"term": {"c": {"value": "bar"}}}
+			]}}`,
+		},
+		"simple_or": {
+			input: expr.OR(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want: `{"bool": {"should": [
+				{"term": {"a": {"value": 5}}},
+				{"term": {"b": {"value": "foo"}}}
+			]}}`,
+		},
+		"simple_not": {
+			input: expr.NOT(expr.Eq("a", 1)),
+			want:  `{"bool": {"must_not": [{"term": {"a": {"value": 1}}}]}}`,
+		},
+		"must_and_must_not": {
+			input: expr.AND(expr.MUST(expr.Eq("a", 1)), expr.MUSTNOT(expr.Eq("b", 2))),
+			want: `{"bool": {
+				"must": [{"term": {"a": {"value": 1}}}],
+				"must_not": [{"term": {"b": {"value": 2}}}]
+			}}`,
+		},
+		"ranges_are_filters": {
+			input: expr.AND(expr.Eq("a", 1), expr.Rang("b", 1, 10, true)),
+			want: `{"bool": {
+				"must": [{"term": {"a": {"value": 1}}}],
+				"filter": [{"range": {"b": {"gte": 1, "lte": 10}}}]
+			}}`,
+		},
+		"exists": {
+			input: expr.EXISTS("a"),
+			want:  `{"exists": {"field": "a"}}`,
+		},
+		"exists_is_a_filter": {
+			input: expr.AND(expr.Eq("a", 1), expr.NOT(expr.EXISTS("b"))),
+			want: `{"bool": {
+				"must": [{"term": {"a": {"value": 1}}}],
+				"must_not": [{"exists": {"field": "b"}}]
+			}}`,
+		},
+		"exclusive_range": {
+			input: expr.Rang("a", "bar", "foo", false),
+			want:  `{"range": {"a": {"gt": "bar", "lt": "foo"}}}`,
+		},
+		"unbound_range": {
+			input: expr.Rang("a", "*", 10, true),
+			want:  `{"range": {"a": {"lte": 10}}}`,
+		},
+		"half_open_range": {
+			input: expr.RangBounds("a", 18, 65, true, false),
+			want:  `{"range": {"a": {"gte": 18, "lt": 65}}}`,
+		},
+		"comparisons": {
+			input: expr.OR(expr.GREATER("a", 1), expr.LESSEQ("b", 2)),
+			want: `{"bool": {"should": [
+				{"range": {"a": {"gt": 1}}},
+				{"range": {"b": {"lte": 2}}}
+			]}}`,
+		},
+		"in_list": {
+			input: expr.IN("a", expr.LIST(expr.Lit("b"), expr.Lit("c"))),
+			want:  `{"terms": {"a": ["b", "c"]}}`,
+		},
+		"wildcard": {
+			input: expr.LIKE("a", "b*"),
+			want:  `{"wildcard": {"a": {"value": "b*"}}}`,
+		},
+		"regexp": {
+			input: expr.LIKE("a", expr.REGEXP("/b.*ar/")),
+			want:  `{"regexp": {"a": {"value": "b.*ar"}}}`,
+		},
+		"fuzzy_field": {
+			input: expr.FUZZY(expr.Eq("a", "foo"), 2),
+			want:  `{"fuzzy": {"a": {"value": "foo", "fuzziness": 2}}}`,
+		},
+		"fuzzy_value": {
+			input: expr.Eq("a", expr.FUZZY("foo", 3)),
+			want:  `{"fuzzy": {"a": {"value": "foo", "fuzziness": 3}}}`,
+		},
+		"fuzzy_literal": {
+			input: expr.FUZZY("foo"),
+			want:  `{"multi_match": {"query": "foo", "fuzziness": 1}}`,
+		},
+		"proximity_field": {
+			input: expr.PROXIMITY(expr.Eq("a", "foo bar"), 3),
+			want:  `{"match_phrase": {"a": {"query": "foo bar", "slop": 3}}}`,
+		},
+		"proximity_literal": {
+			input: expr.PROXIMITY(expr.Lit("foo bar"), 2),
+			want:  `{"multi_match": {"query": "foo bar", "type": "phrase", "slop": 2}}`,
+		},
+		"boost_term": {
+			input: expr.BOOST(expr.Eq("a", "foo"), 2.5),
+			want:  `{"term": {"a": {"value": "foo", "boost": 2.5}}}`,
+		},
+		"boost_bool": {
+			input: expr.BOOST(expr.OR(expr.Eq("a", 1), expr.Eq("b", 2)), 2),
+			want: `{"bool": {"boost": 2, "should": [
+				{"term": {"a": {"value": 1}}},
+				{"term": {"b": {"value": 2}}}
+			]}}`,
+		},
+		"bare_literal": {
+			input: expr.Lit("foo bar"),
+			want:  `{"multi_match": {"query": "foo bar", "type": "phrase"}}`,
+		},
+		"bare_wildcard": {
+			input: expr.WILD("fo*"),
+			want:  `{"query_string": {"query": "fo*"}}`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewElasticsearchDriver().Render(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			var gotJSON, wantJSON any
+			if err := json.Unmarshal([]byte(got), &gotJSON); err != nil {
+				t.Fatalf("rendered invalid json: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &wantJSON); err != nil {
+				t.Fatalf("invalid json in test case: %v", err)
+			}
+
+			if !reflect.DeepEqual(wantJSON, gotJSON) {
+				t.Fatalf(errTemplate, "generated query does not match", tc.want, got)
+			}
+		})
+	}
+}
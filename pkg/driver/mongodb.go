@@ -0,0 +1,229 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// MongoDriver transforms a parsed lucene expression into a MongoDB filter document. The filter is
+// built from plain maps and slices so it can be converted to bson without depending on a mongo driver.
+type MongoDriver struct {
+	// base holds the options shared with the other drivers. The column map is applied to the field
+	// names in the filter and the clock resolves relative dates.
+	base Base
+}
+
+// NewMongoDriver creates a new driver that will output a parsed lucene expression as a MongoDB filter.
+func NewMongoDriver(opts ...Option) MongoDriver {
+	d := MongoDriver{}
+	for _, opt := range opts {
+		opt(&d.base)
+	}
+	return d
+}
+
+// Render will render the expression as a json encoded filter document.
+func (d MongoDriver) Render(e *expr.Expression) (s string, err error) {
+	f, err := d.RenderFilter(e)
+	if err != nil {
+		return s, err
+	}
+
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return s, err
+	}
+	return string(raw), nil
+}
+
+// RenderFilter will render the expression as a filter document that can be passed to
+// a mongo driver, for example as a bson.M. Relative dates like now-7d are resolved against the
+// current time, or the clock passed with WithClock.
+func (d MongoDriver) RenderFilter(e *expr.Expression) (f map[string]any, err error) {
+	return d.filter(expr.ResolveDates(e, d.base.clock()))
+}
+
+func (d MongoDriver) filter(e *expr.Expression) (f map[string]any, err error) {
+	if e == nil {
+		return map[string]any{}, nil
+	}
+
+	switch e.Op {
+	case expr.And:
+		return d.compound("$and", flatten(e, expr.And))
+	case expr.Or:
+		return d.compound("$or", flatten(e, expr.Or))
+	case expr.Not, expr.MustNot:
+		return d.compound("$nor", []*expr.Expression{subExpr(e.Left)})
+	case expr.Must, expr.Boost:
+		// must and boosts have no meaning outside of scoring so they are just the sub expression
+		return d.filter(subExpr(e.Left))
+	case expr.Equals:
+		return d.field(e, "$eq")
+	case expr.Greater:
+		return d.field(e, "$gt")
+	case expr.GreaterEq:
+		return d.field(e, "$gte")
+	case expr.Less:
+		return d.field(e, "$lt")
+	case expr.LessEq:
+		return d.field(e, "$lte")
+	case expr.In:
+		return d.in(e)
+	case expr.Range:
+		return d.rang(e)
+	case expr.Like:
+		return d.like(e)
+	case expr.Exists:
+		// $exists matches explicit nulls so compare against null instead to match the sql drivers
+		field, err := d.fieldOf(e.Left)
+		if err != nil {
+			return f, err
+		}
+		return map[string]any{field: map[string]any{"$ne": nil}}, nil
+	case expr.Literal:
+		// a term without a field can only be matched with a text index
+		val, err := valueOf(e)
+		if err != nil {
+			return f, err
+		}
+		return map[string]any{"$text": map[string]any{"$search": fmt.Sprintf("%v", val)}}, nil
+	}
+
+	return f, fmt.Errorf("unable to render operator [%s]", e.Op)
+}
+
+// fieldOf returns the document field the expression refers to, mapped through the column map if
+// there is one
+func (d MongoDriver) fieldOf(in any) (field string, err error) {
+	field, err = fieldOf(in)
+	if err != nil || d.base.columns == nil {
+		return field, err
+	}
+
+	mapped, found := d.base.columns[field]
+	if !found {
+		return field, &UnknownFieldError{Field: field}
+	}
+	return mapped, nil
+}
+
+func (d MongoDriver) compound(op string, exprs []*expr.Expression) (f map[string]any, err error) {
+	clauses := []any{}
+	for _, e := range exprs {
+		if e == nil {
+			return f, fmt.Errorf("%s must only contain expressions", op)
+		}
+
+		clause, err := d.filter(e)
+		if err != nil {
+			return f, err
+		}
+		clauses = append(clauses, clause)
+	}
+	return map[string]any{op: clauses}, nil
+}
+
+func (d MongoDriver) field(e *expr.Expression, op string) (f map[string]any, err error) {
+	field, err := d.fieldOf(e.Left)
+	if err != nil {
+		return f, err
+	}
+
+	val, err := valueOf(e.Right)
+	if err != nil {
+		return f, err
+	}
+	return map[string]any{field: map[string]any{op: val}}, nil
+}
+
+func (d MongoDriver) in(e *expr.Expression) (f map[string]any, err error) {
+	field, err := d.fieldOf(e.Left)
+	if err != nil {
+		return f, err
+	}
+
+	list := subExpr(e.Right)
+	if list == nil || list.Op != expr.List {
+		return f, fmt.Errorf("IN must have a list on the right side, got %T", e.Right)
+	}
+
+	exprs, ok := list.Left.([]*expr.Expression)
+	if !ok {
+		return f, fmt.Errorf("LIST must contain expressions, got %T", list.Left)
+	}
+
+	vals := []any{}
+	for _, v := range exprs {
+		val, err := valueOf(v)
+		if err != nil {
+			return f, err
+		}
+		vals = append(vals, val)
+	}
+	return map[string]any{field: map[string]any{"$in": vals}}, nil
+}
+
+func (d MongoDriver) rang(e *expr.Expression) (f map[string]any, err error) {
+	field, err := d.fieldOf(e.Left)
+	if err != nil {
+		return f, err
+	}
+
+	boundary, ok := e.Right.(*expr.RangeBoundary)
+	if !ok {
+		return f, fmt.Errorf("RANGE must have a range boundary on the right side, got %T", e.Right)
+	}
+
+	minOp, maxOp := "$gt", "$lt"
+	if boundary.MinInclusive {
+		minOp = "$gte"
+	}
+	if boundary.MaxInclusive {
+		maxOp = "$lte"
+	}
+
+	cond := map[string]any{}
+	if !isUnbounded(boundary.Min) {
+		cond[minOp], err = valueOf(boundary.Min)
+		if err != nil {
+			return f, err
+		}
+	}
+	if !isUnbounded(boundary.Max) {
+		cond[maxOp], err = valueOf(boundary.Max)
+		if err != nil {
+			return f, err
+		}
+	}
+
+	if len(cond) == 0 {
+		return f, fmt.Errorf("range on %s must have at least one bound", field)
+	}
+	return map[string]any{field: cond}, nil
+}
+
+// like renders wildcards and regexps with $regex. Lucene patterns match the entire term so the
+// generated regex is anchored on both ends.
+func (d MongoDriver) like(e *expr.Expression) (f map[string]any, err error) {
+	field, err := d.fieldOf(e.Left)
+	if err != nil {
+		return f, err
+	}
+
+	right := subExpr(e.Right)
+	if right == nil {
+		return f, fmt.Errorf("LIKE must have a wildcard or regexp on the right side, got %T", e.Right)
+	}
+
+	pattern := fmt.Sprintf("%v", right.Left)
+	switch right.Op {
+	case expr.Wild:
+		return map[string]any{field: map[string]any{"$regex": expr.WildcardToRegexp(pattern)}}, nil
+	case expr.Regexp:
+		return map[string]any{field: map[string]any{"$regex": fmt.Sprintf("^(?:%s)$", stripRegexpDelimiters(pattern))}}, nil
+	}
+	return f, fmt.Errorf("unable to render LIKE with a [%s] pattern", right.Op)
+}
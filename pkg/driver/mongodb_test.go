@@ -0,0 +1,163 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+func TestMongoDriver(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		want  string
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input: expr.Eq("a", 5),
+			want:  `{"a": {"$eq": 5}}`,
+		},
+		"flattened_and": {
+			input: expr.AND(expr.AND(expr.Eq("a", 5), expr.Eq("b", "foo")), expr.Eq("c", "bar")),
+			want: `{"$and": [
+				{"a": {"$eq": 5}},
+				{"b": {"$eq": "foo"}},
+				{"c": {"$eq": "bar"}}
+			]}`,
+		},
+		"simple_or": {
+			input: expr.OR(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want:  `{"$or": [{"a": {"$eq": 5}}, {"b": {"$eq": "foo"}}]}`,
+		},
+		"simple_not": {
+			input: expr.NOT(expr.Eq("a", 1)),
+			want:  `{"$nor": [{"a": {"$eq": 1}}]}`,
+		},
+		"boost_ignored": {
+			input: expr.BOOST(expr.Eq("a", "b"), 2),
+			want:  `{"a": {"$eq": "b"}}`,
+		},
+		"must_not": {
+			input: expr.MUSTNOT(expr.Eq("a", 1)),
+			want:  `{"$nor": [{"a": {"$eq": 1}}]}`,
+		},
+		"must_ignored": {
+			input: expr.MUST(expr.Eq("a", 1)),
+			want:  `{"a": {"$eq": 1}}`,
+		},
+		"in_list": {
+			input: expr.IN("a", expr.LIST(expr.Lit("b"), expr.Lit("c"))),
+			want:  `{"a": {"$in": ["b", "c"]}}`,
+		},
+		"inclusive_range": {
+			input: expr.Rang("a", 1, 10, true),
+			want:  `{"a": {"$gte": 1, "$lte": 10}}`,
+		},
+		"exclusive_range": {
+			input: expr.Rang("a", "bar", "foo", false),
+			want:  `{"a": {"$gt": "bar", "$lt": "foo"}}`,
+		},
+		"unbound_range": {
+			input: expr.Rang("a", 1, "*", false),
+			want:  `{"a": {"$gt": 1}}`,
+		},
+		"exists": {
+			input: expr.EXISTS("a"),
+			want:  `{"a": {"$ne": null}}`,
+		},
+		"not_exists": {
+			input: expr.NOT(expr.EXISTS("a")),
+			want:  `{"$nor": [{"a": {"$ne": null}}]}`,
+		},
+		"half_open_range": {
+			input: expr.RangBounds("a", 18, 65, true, false),
+			want:  `{"a": {"$gte": 18, "$lt": 65}}`,
+		},
+		"comparisons": {
+			input: expr.AND(
+				expr.AND(expr.GREATER("a", 1), expr.GREATEREQ("b", 2)),
+				expr.AND(expr.LESS("c", 3), expr.LESSEQ("d", 4)),
+			),
+			want: `{"$and": [
+				{"a": {"$gt": 1}},
+				{"b": {"$gte": 2}},
+				{"c": {"$lt": 3}},
+				{"d": {"$lte": 4}}
+			]}`,
+		},
+		"wildcard": {
+			input: expr.LIKE("a", "b.c*d?"),
+			want:  `{"a": {"$regex": "^b\\.c.*d.$"}}`,
+		},
+		"regexp": {
+			input: expr.LIKE("a", expr.REGEXP("/b.*ar/")),
+			want:  `{"a": {"$regex": "^(?:b.*ar)$"}}`,
+		},
+		"bare_literal": {
+			input: expr.Lit("foo"),
+			want:  `{"$text": {"$search": "foo"}}`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewMongoDriver().Render(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			var gotJSON, wantJSON any
+			if err := json.Unmarshal([]byte(got), &gotJSON); err != nil {
+				t.Fatalf("rendered invalid json: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tc.want), &wantJSON); err != nil {
+				t.Fatalf("invalid json in test case: %v", err)
+			}
+
+			if !reflect.DeepEqual(wantJSON, gotJSON) {
+				t.Fatalf(errTemplate, "generated filter does not match", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMongoDriverDates(t *testing.T) {
+	now := time.Date(2024, 3, 15, 13, 30, 0, 0, time.UTC)
+	d := NewMongoDriver(WithClock(func() time.Time { return now }))
+
+	got, err := d.RenderFilter(expr.Rang("created_at", "now-7d/d", "now", true))
+	if err != nil {
+		t.Fatalf("got an unexpected error when rendering: %v", err)
+	}
+
+	want := map[string]any{"created_at": map[string]any{
+		"$gte": time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC),
+		"$lte": now,
+	}}
+	if !reflect.DeepEqual(want, got) {
+		t.Fatalf(errTemplate, "generated filter does not match", want, got)
+	}
+}
+
+func TestMongoDriverColumnMap(t *testing.T) {
+	d := NewMongoDriver(WithColumnMap(map[string]string{"author": "user.name"}))
+
+	got, err := d.Render(expr.AND(expr.Eq("author", "bob"), expr.Rang("author", "a", "c", true)))
+	if err != nil {
+		t.Fatalf("got an unexpected error when rendering: %v", err)
+	}
+	want := `{"$and":[{"user.name":{"$eq":"bob"}},{"user.name":{"$gte":"a","$lte":"c"}}]}`
+	if got != want {
+		t.Fatalf(errTemplate, "generated filter does not match", want, got)
+	}
+
+	_, err = d.Render(expr.OR(expr.Eq("author", "bob"), expr.Eq("secret", 1)))
+	var ferr *UnknownFieldError
+	if !errors.As(err, &ferr) || ferr.Field != "secret" {
+		t.Fatalf("expected an UnknownFieldError for secret but got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,81 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// MySQLDriver transforms a parsed lucene expression to a mysql filter.
+type MySQLDriver struct {
+	Base
+}
+
+// NewMySQLDriver creates a new driver that will output a parsed lucene expression as a MySQL filter.
+func NewMySQLDriver(opts ...Option) MySQLDriver {
+	fns := map[expr.Operator]RenderFN{
+		expr.Literal: literal,
+		expr.Like:    mysqlLike,
+	}
+
+	for op, sharedFN := range Shared {
+		_, found := fns[op]
+		if !found {
+			fns[op] = sharedFN
+		}
+	}
+
+	b := Base{
+		renderFNs:   fns,
+		quoteColumn: backtickColumn,
+		placeholder: questionPlaceholder,
+		matchFNs: map[expr.Operator]MatchFN{
+			expr.Wild:   mysqlLikeParam,
+			expr.Regexp: mysqlRegexpParam,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&b)
+	}
+
+	return MySQLDriver{b}
+}
+
+// mysqlLike uses REGEXP for regular expressions and LIKE for wildcards since mysql
+// does not support SIMILAR TO or ~.
+func mysqlLike(left, right string) (string, error) {
+	if len(right) >= 4 && right[1] == '/' && right[len(right)-2] == '/' {
+		return fmt.Sprintf("%s REGEXP '%s'", left, right[2:len(right)-2]), nil
+	}
+
+	if len(right) >= 2 && right[0] == '\'' && right[len(right)-1] == '\'' {
+		right = fmt.Sprintf("'%s'", translateWildcards(right[1:len(right)-1], "%_"))
+	}
+	return fmt.Sprintf("%s LIKE %s", left, right), nil
+}
+
+func mysqlLikeParam(column, placeholder, pattern string) (string, any, error) {
+	return fmt.Sprintf("%s LIKE %s", column, placeholder), translateWildcards(pattern, "%_"), nil
+}
+
+func mysqlRegexpParam(column, placeholder, pattern string) (string, any, error) {
+	return fmt.Sprintf("%s REGEXP %s", column, placeholder), stripRegexpDelimiters(pattern), nil
+}
+
+// backtickColumn quotes a column in backticks if it contains a space or was already
+// quoted with double quotes, which mysql would treat as a string.
+func backtickColumn(column string) string {
+	if len(column) >= 2 && column[0] == '"' && column[len(column)-1] == '"' {
+		column = column[1 : len(column)-1]
+	} else if !strings.Contains(column, " ") {
+		return column
+	}
+	return fmt.Sprintf("`%s`", strings.ReplaceAll(column, "`", "``"))
+}
+
+// questionPlaceholder renders mysql style placeholders (?)
+func questionPlaceholder(n int) string {
+	return "?"
+}
@@ -0,0 +1,176 @@
+package driver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+func TestMySQLDriver(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		want  string
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input: expr.Eq("a", 5),
+			want:  "a = 5",
+		},
+		"not_exists": {
+			input: expr.NOT(expr.EXISTS("a b")),
+			want:  "`a b` IS NULL",
+		},
+		"simple_and": {
+			input: expr.AND(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want:  `(a = 5) AND (b = 'foo')`,
+		},
+		"simple_or": {
+			input: expr.OR(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want:  `(a = 5) OR (b = 'foo')`,
+		},
+		"simple_not": {
+			input: expr.NOT(expr.Eq("a", 1)),
+			want:  `NOT(a = 1)`,
+		},
+		"wildcard_like": {
+			input: expr.LIKE("a", "b*z?"),
+			want:  `a LIKE 'b%z_'`,
+		},
+		"wildcard_like_escapes_sql_wildcards": {
+			input: expr.LIKE("a", "50%_*"),
+			want:  `a LIKE '50\%\_%'`,
+		},
+		"regexp_like": {
+			input: expr.LIKE("a", expr.REGEXP("/b*ar/")),
+			want:  `a REGEXP 'b*ar'`,
+		},
+		"string_range": {
+			input: expr.Rang("a", "foo", "bar", true),
+			want:  `a BETWEEN 'foo' AND 'bar'`,
+		},
+		"int_range": {
+			input: expr.Rang("a", 1, 10, true),
+			want:  `a >= 1 AND a <= 10`,
+		},
+		"int_range_exlusive": {
+			input: expr.Rang("a", 1, 10, false),
+			want:  `a > 1 AND a < 10`,
+		},
+		"lt_range": {
+			input: expr.Rang("a", "*", 10, false),
+			want:  `a < 10`,
+		},
+		"gte_range": {
+			input: expr.Rang("a", 1, "*", true),
+			want:  `a >= 1`,
+		},
+		"lt": {
+			input: expr.LESS("a", 10),
+			want:  `a < 10`,
+		},
+		"gte": {
+			input: expr.GREATEREQ("a", 10),
+			want:  `a >= 10`,
+		},
+		"in_list": {
+			input: expr.IN("a", expr.LIST(expr.Lit("b"), expr.Lit("c"))),
+			want:  `a IN ('b', 'c')`,
+		},
+		"must_ignored": {
+			input: expr.MUST(expr.Eq("a", 1)),
+			want:  `a = 1`,
+		},
+		"nested_filter": {
+			input: expr.AND(
+				expr.OR(
+					expr.Eq("a", "foo"),
+					expr.Eq("b", expr.REGEXP("/b*ar/")),
+				),
+				expr.NOT(expr.Rang("c", 1, "*", false)),
+			),
+			want: "((a = 'foo') OR (b REGEXP 'b*ar')) AND (NOT(c > 1))",
+		},
+		"space_in_fieldname": {
+			input: expr.Eq("a b", 1),
+			want:  "`a b` = 1",
+		},
+		"quoted_column_name": {
+			input: expr.Eq(`"foobar"`, 1),
+			want:  "`foobar` = 1",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewMySQLDriver().Render(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestMySQLDriverParams(t *testing.T) {
+	type tc struct {
+		input      *expr.Expression
+		want       string
+		wantParams []any
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input:      expr.Eq("a", "O'Brien"),
+			want:       "a = ?",
+			wantParams: []any{"O'Brien"},
+		},
+		"wildcard_like": {
+			input:      expr.LIKE("a", "50%*"),
+			want:       `a LIKE ?`,
+			wantParams: []any{`50\%%`},
+		},
+		"regexp_like": {
+			input:      expr.LIKE("a", expr.REGEXP("/b*ar/")),
+			want:       `a REGEXP ?`,
+			wantParams: []any{"b*ar"},
+		},
+		"in_list": {
+			input:      expr.IN("a", expr.LIST(expr.Lit("b"), expr.Lit("c"))),
+			want:       `a IN (?, ?)`,
+			wantParams: []any{"b", "c"},
+		},
+		"range": {
+			input:      expr.Rang("a", 1, 10, false),
+			want:       `a > ? AND a < ?`,
+			wantParams: []any{1, 10},
+		},
+		"space_in_fieldname": {
+			input:      expr.AND(expr.Eq("a b", 1), expr.NOT(expr.LESS("c", 2))),
+			want:       "(`a b` = ?) AND (NOT(c < ?))",
+			wantParams: []any{1, 2},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, params, err := NewMySQLDriver().RenderParam(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+
+			if !reflect.DeepEqual(tc.wantParams, params) {
+				t.Fatalf(errTemplate, "generated params do not match", fmt.Sprint(tc.wantParams), fmt.Sprint(params))
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// PlaceholderFN renders the placeholder for the nth (1 indexed) bound parameter in a query.
+type PlaceholderFN func(n int) string
+
+// MatchFN renders a column matched against a bound wildcard or regexp pattern. It takes the serialized
+// column, the placeholder for the pattern, and the raw lucene pattern and returns the sql along with
+// the value that should be bound to the placeholder.
+type MatchFN func(column, placeholder, pattern string) (s string, param any, err error)
+
+// RenderParam will render the expression as a parameterized sql filter. Every value in the expression is
+// replaced with a placeholder and returned in params in the same order so the output can be passed straight
+// to database/sql.
+func (b Base) RenderParam(e *expr.Expression) (s string, params []any, err error) {
+	p := &paramRenderer{Base: b}
+	s, err = p.render(expr.ResolveDates(e, b.clock()))
+	if err != nil {
+		return s, nil, err
+	}
+	return s, p.params, nil
+}
+
+// paramRenderer tracks the bound parameters while rendering a parameterized query.
+type paramRenderer struct {
+	Base
+	params []any
+}
+
+func (p *paramRenderer) render(e *expr.Expression) (s string, err error) {
+	if e == nil {
+		return "", nil
+	}
+
+	switch e.Op {
+	case expr.Like:
+		return p.like(e)
+	case expr.Range:
+		return p.rang(e)
+	}
+
+	if field, ok := missingField(e); ok {
+		left, err := p.serialize(field)
+		if err != nil {
+			return s, err
+		}
+		return isNull(left, "")
+	}
+
+	left, err := p.serialize(e.Left)
+	if err != nil {
+		return s, err
+	}
+
+	right, err := p.serialize(e.Right)
+	if err != nil {
+		return s, err
+	}
+
+	fn, ok := p.renderFNs[e.Op]
+	if !ok {
+		return s, fmt.Errorf("unable to render operator [%s]", e.Op)
+	}
+
+	return fn(left, right)
+}
+
+func (p *paramRenderer) serialize(in any) (s string, err error) {
+	if in == nil {
+		return "", nil
+	}
+
+	switch v := in.(type) {
+	case *expr.Expression:
+		return p.render(v)
+	case []*expr.Expression:
+		strs := []string{}
+		for _, e := range v {
+			s, err = p.render(e)
+			if err != nil {
+				return s, err
+			}
+			strs = append(strs, s)
+		}
+		return strings.Join(strs, ", "), nil
+	case *expr.RangeBoundary:
+		return s, fmt.Errorf("range boundary must be rendered as part of a range")
+	case expr.Column:
+		return p.Base.serialize(v)
+	default:
+		return p.bind(v), nil
+	}
+}
+
+// bind adds the value to the bound parameters and returns its placeholder
+func (p *paramRenderer) bind(v any) string {
+	p.params = append(p.params, v)
+	return p.placeholder(len(p.params))
+}
+
+func (p *paramRenderer) like(e *expr.Expression) (s string, err error) {
+	left, err := p.serialize(e.Left)
+	if err != nil {
+		return s, err
+	}
+
+	right, ok := e.Right.(*expr.Expression)
+	if !ok {
+		return s, fmt.Errorf("LIKE must have a wildcard or regexp on the right side, got %T", e.Right)
+	}
+
+	fn, ok := p.matchFNs[right.Op]
+	if !ok {
+		return s, fmt.Errorf("unable to render LIKE with a [%s] pattern", right.Op)
+	}
+
+	s, param, err := fn(left, p.placeholder(len(p.params)+1), fmt.Sprintf("%v", right.Left))
+	if err != nil {
+		return s, err
+	}
+	p.params = append(p.params, param)
+	return s, nil
+}
+
+// rang renders a range as a pair of comparisons so each bound can be passed as a parameter.
+// Unbounded sides (*) are dropped entirely.
+func (p *paramRenderer) rang(e *expr.Expression) (s string, err error) {
+	return p.compareRange(e, p.serialize)
+}
+
+// isUnbounded checks if a range boundary is the open ended * boundary
+func isUnbounded(in any) bool {
+	e, ok := in.(*expr.Expression)
+	if !ok {
+		return in == "*"
+	}
+	return e.Left == "*"
+}
+
+// dollarPlaceholder renders postgres style placeholders ($1, $2, ...)
+func dollarPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// similarToParam matches a column with SIMILAR TO, translating the lucene wildcards into
+// their sql equivalents and escaping everything else SIMILAR TO would treat as special.
+func similarToParam(column, placeholder, pattern string) (string, any, error) {
+	return fmt.Sprintf("%s SIMILAR TO %s", column, placeholder), translateWildcards(pattern, "%_|*+?{}()[]"), nil
+}
+
+// regexpMatchParam matches a column against a posix regular expression with ~. The
+// surrounding /'s from the lucene syntax are stripped from the bound pattern.
+func regexpMatchParam(column, placeholder, pattern string) (string, any, error) {
+	return fmt.Sprintf("%s ~ %s", column, placeholder), stripRegexpDelimiters(pattern), nil
+}
+
+// translateWildcards converts lucene wildcards (* and ?) into sql wildcards (% and _). Any lucene
+// escaped characters are kept literally and any character in special is escaped with a backslash.
+func translateWildcards(pattern, special string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			if strings.ContainsRune(special, r) || r == '\\' {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			sb.WriteRune('%')
+		case r == '?':
+			sb.WriteRune('_')
+		case strings.ContainsRune(special, r):
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func stripRegexpDelimiters(pattern string) string {
+	if len(pattern) >= 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
+		return pattern[1 : len(pattern)-1]
+	}
+	return pattern
+}
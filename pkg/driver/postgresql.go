@@ -1,6 +1,11 @@
 package driver
 
-import "github.com/grindlemire/go-lucene/pkg/lucene/expr"
+import (
+	"fmt"
+	"time"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
 
 // PostgresDriver transforms a parsed lucene expression to a sql filter.
 type PostgresDriver struct {
@@ -8,7 +13,7 @@ type PostgresDriver struct {
 }
 
 // NewPostgresDriver creates a new driver that will output a parsed lucene expression as a SQL filter.
-func NewPostgresDriver() PostgresDriver {
+func NewPostgresDriver(opts ...Option) PostgresDriver {
 	fns := map[expr.Operator]RenderFN{
 		expr.Literal: literal,
 	}
@@ -20,9 +25,24 @@ func NewPostgresDriver() PostgresDriver {
 		}
 	}
 
-	return PostgresDriver{
-		Base{
-			renderFNs: fns,
+	b := Base{
+		renderFNs:   fns,
+		placeholder: dollarPlaceholder,
+		timestamp:   postgresTimestamp,
+		matchFNs: map[expr.Operator]MatchFN{
+			expr.Wild:   similarToParam,
+			expr.Regexp: regexpMatchParam,
 		},
 	}
+
+	for _, opt := range opts {
+		opt(&b)
+	}
+
+	return PostgresDriver{b}
+}
+
+// postgresTimestamp renders a date as a timestamptz literal
+func postgresTimestamp(t time.Time) string {
+	return fmt.Sprintf("TIMESTAMPTZ '%s'", t.Format(time.RFC3339Nano))
 }
@@ -1,7 +1,11 @@
 package driver
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
 )
@@ -55,6 +59,14 @@ func TestSQLDriver(t *testing.T) {
 			input: expr.Rang("a", 1, 10, false),
 			want:  `a > 1 AND a < 10`,
 		},
+		"int_range_half_open": {
+			input: expr.RangBounds("a", 1, 10, true, false),
+			want:  `a >= 1 AND a < 10`,
+		},
+		"string_range_half_open": {
+			input: expr.RangBounds("a", "bar", "foo", false, true),
+			want:  `a > 'bar' AND a <= 'foo'`,
+		},
 		"float_range": {
 			input: expr.Rang("a", 1.0, 10.0, true),
 			want:  `a >= 1 AND a <= 10`,
@@ -145,3 +157,298 @@ func TestSQLDriver(t *testing.T) {
 		})
 	}
 }
+
+func TestSQLDriverParams(t *testing.T) {
+	type tc struct {
+		input      *expr.Expression
+		want       string
+		wantParams []any
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input:      expr.Eq("a", 5),
+			want:       "a = $1",
+			wantParams: []any{5},
+		},
+		"exists_binds_nothing": {
+			input:      expr.AND(expr.EXISTS("a"), expr.MUSTNOT(expr.EXISTS("b"))),
+			want:       "(a IS NOT NULL) AND (b IS NULL)",
+			wantParams: nil,
+		},
+		"quote_in_value": {
+			input:      expr.Eq("name", "O'Brien"),
+			want:       "name = $1",
+			wantParams: []any{"O'Brien"},
+		},
+		"simple_and": {
+			input:      expr.AND(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want:       `(a = $1) AND (b = $2)`,
+			wantParams: []any{5, "foo"},
+		},
+		"simple_or": {
+			input:      expr.OR(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want:       `(a = $1) OR (b = $2)`,
+			wantParams: []any{5, "foo"},
+		},
+		"simple_not": {
+			input:      expr.NOT(expr.Eq("a", 1)),
+			want:       `NOT(a = $1)`,
+			wantParams: []any{1},
+		},
+		"wildcard_like": {
+			input:      expr.LIKE("a", "b*z?"),
+			want:       `a SIMILAR TO $1`,
+			wantParams: []any{"b%z_"},
+		},
+		"wildcard_like_escapes_special_chars": {
+			input:      expr.LIKE("a", expr.WILD(`50%_(a|b)\*x*`)),
+			want:       `a SIMILAR TO $1`,
+			wantParams: []any{`50\%\_\(a\|b\)\*x%`},
+		},
+		"regexp_like": {
+			input:      expr.LIKE("a", expr.REGEXP("/b*ar/")),
+			want:       `a ~ $1`,
+			wantParams: []any{"b*ar"},
+		},
+		"in_list": {
+			input:      expr.IN("a", expr.LIST(expr.Lit("b"), expr.Lit("c"), expr.Lit(1))),
+			want:       `a IN ($1, $2, $3)`,
+			wantParams: []any{"b", "c", 1},
+		},
+		"int_range": {
+			input:      expr.Rang("a", 1, 10, true),
+			want:       `a >= $1 AND a <= $2`,
+			wantParams: []any{1, 10},
+		},
+		"int_range_exclusive": {
+			input:      expr.Rang("a", 1, 10, false),
+			want:       `a > $1 AND a < $2`,
+			wantParams: []any{1, 10},
+		},
+		"int_range_half_open": {
+			input:      expr.RangBounds("a", 1, 10, false, true),
+			want:       `a > $1 AND a <= $2`,
+			wantParams: []any{1, 10},
+		},
+		"string_range": {
+			input:      expr.Rang("a", "bar", "foo", false),
+			want:       `a > $1 AND a < $2`,
+			wantParams: []any{"bar", "foo"},
+		},
+		"lt_range": {
+			input:      expr.Rang("a", "*", 10, false),
+			want:       `a < $1`,
+			wantParams: []any{10},
+		},
+		"gte_range": {
+			input:      expr.Rang("a", 1.5, "*", true),
+			want:       `a >= $1`,
+			wantParams: []any{1.5},
+		},
+		"comparisons": {
+			input: expr.AND(
+				expr.AND(expr.LESS("a", 10), expr.LESSEQ("b", 11)),
+				expr.AND(expr.GREATER("c", 12), expr.GREATEREQ("d", 13)),
+			),
+			want:       `((a < $1) AND (b <= $2)) AND ((c > $3) AND (d >= $4))`,
+			wantParams: []any{10, 11, 12, 13},
+		},
+		"must_ignored": {
+			input:      expr.MUST(expr.Eq("a", 1)),
+			want:       `a = $1`,
+			wantParams: []any{1},
+		},
+		"must_not": {
+			input:      expr.MUSTNOT(expr.Eq("a", 1)),
+			want:       `NOT(a = $1)`,
+			wantParams: []any{1},
+		},
+		"space_in_fieldname": {
+			input:      expr.Eq("a b", 1),
+			want:       `"a b" = $1`,
+			wantParams: []any{1},
+		},
+		"nested_filter": {
+			input: expr.AND(
+				expr.OR(
+					expr.Eq("a", "foo"),
+					expr.Eq("b", expr.REGEXP("/b*ar/")),
+				),
+				expr.NOT(expr.Rang("c", "aaa", "*", false)),
+			),
+			want:       `((a = $1) OR (b ~ $2)) AND (NOT(c > $3))`,
+			wantParams: []any{"foo", "b*ar", "aaa"},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, params, err := NewPostgresDriver().RenderParam(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+
+			if !reflect.DeepEqual(tc.wantParams, params) {
+				t.Fatalf(errTemplate, "generated params do not match", fmt.Sprint(tc.wantParams), fmt.Sprint(params))
+			}
+		})
+	}
+}
+
+func TestSQLDriverColumnMap(t *testing.T) {
+	type tc struct {
+		input      *expr.Expression
+		want       string
+		wantParams []any
+		err        string
+	}
+
+	columns := map[string]string{
+		"author": "users.display_name",
+		"price":  "(data->>'price')::numeric",
+		"title":  "title",
+	}
+
+	tcs := map[string]tc{
+		"mapped_column": {
+			input:      expr.Eq("author", "bob"),
+			want:       "users.display_name = $1",
+			wantParams: []any{"bob"},
+		},
+		"mapped_expression_range": {
+			input:      expr.Rang("price", 1, 10, true),
+			want:       "(data->>'price')::numeric >= $1 AND (data->>'price')::numeric <= $2",
+			wantParams: []any{1, 10},
+		},
+		"mapped_compound": {
+			input:      expr.AND(expr.LIKE("title", "foo*"), expr.IN("author", expr.LIST(expr.Lit("a"), expr.Lit("b")))),
+			want:       "(title SIMILAR TO $1) AND (users.display_name IN ($2, $3))",
+			wantParams: []any{"foo%", "a", "b"},
+		},
+		"unknown_field": {
+			input: expr.Eq("password", "hunter2"),
+			err:   "unknown field [password]",
+		},
+		"unknown_field_nested": {
+			input: expr.OR(expr.Eq("title", "foo"), expr.NOT(expr.GREATER("users.id", 1))),
+			err:   "unknown field [users.id]",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			d := NewPostgresDriver(WithColumnMap(columns))
+			got, params, err := d.RenderParam(tc.input)
+			if tc.err != "" {
+				var unknown *UnknownFieldError
+				if !errors.As(err, &unknown) {
+					t.Fatalf("expected an UnknownFieldError but got %v", err)
+				}
+				if err.Error() != tc.err {
+					t.Fatalf(errTemplate, "error does not match", tc.err, err.Error())
+				}
+
+				// the non parameterized rendering should reject the field as well
+				_, err = d.Render(tc.input)
+				if !errors.As(err, &unknown) {
+					t.Fatalf("expected an UnknownFieldError from Render but got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+
+			if !reflect.DeepEqual(tc.wantParams, params) {
+				t.Fatalf(errTemplate, "generated params do not match", fmt.Sprint(tc.wantParams), fmt.Sprint(params))
+			}
+		})
+	}
+}
+
+func TestSQLDriverDates(t *testing.T) {
+	type tc struct {
+		input      *expr.Expression
+		want       string
+		wantParam  string
+		wantParams []any
+	}
+
+	now := time.Date(2024, time.March, 15, 13, 30, 0, 0, time.UTC)
+	today := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	tcs := map[string]tc{
+		"absolute_date": {
+			input:      expr.GREATER("updated", "2024-01-01"),
+			want:       "updated > TIMESTAMPTZ '2024-01-01T00:00:00Z'",
+			wantParam:  "updated > $1",
+			wantParams: []any{time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		"bare_now_comparison": {
+			input:      expr.LESS("created_at", "now"),
+			want:       "created_at < TIMESTAMPTZ '2024-03-15T13:30:00Z'",
+			wantParam:  "created_at < $1",
+			wantParams: []any{now},
+		},
+		"date_math_range": {
+			input:      expr.Rang("created_at", "now-7d/d", "now", true),
+			want:       "created_at >= TIMESTAMPTZ '2024-03-08T00:00:00Z' AND created_at <= TIMESTAMPTZ '2024-03-15T13:30:00Z'",
+			wantParam:  "created_at >= $1 AND created_at <= $2",
+			wantParams: []any{today.AddDate(0, 0, -7), now},
+		},
+		"rounded_equals_matches_the_whole_unit": {
+			input:      expr.Eq("created_at", "now/d"),
+			want:       "created_at >= TIMESTAMPTZ '2024-03-15T00:00:00Z' AND created_at < TIMESTAMPTZ '2024-03-16T00:00:00Z'",
+			wantParam:  "created_at >= $1 AND created_at < $2",
+			wantParams: []any{today, tomorrow},
+		},
+		"rounded_less_eq_includes_the_whole_unit": {
+			input:      expr.LESSEQ("created_at", "now/d"),
+			want:       "created_at < TIMESTAMPTZ '2024-03-16T00:00:00Z'",
+			wantParam:  "created_at < $1",
+			wantParams: []any{tomorrow},
+		},
+		"rounded_greater_excludes_the_whole_unit": {
+			input:      expr.GREATER("created_at", "now/d"),
+			want:       "created_at >= TIMESTAMPTZ '2024-03-16T00:00:00Z'",
+			wantParam:  "created_at >= $1",
+			wantParams: []any{tomorrow},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			d := NewPostgresDriver(WithClock(func() time.Time { return now }))
+
+			got, err := d.Render(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+
+			got, params, err := d.RenderParam(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering params: %v", err)
+			}
+			if tc.wantParam != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.wantParam, got)
+			}
+			if !reflect.DeepEqual(tc.wantParams, params) {
+				t.Fatalf(errTemplate, "generated params do not match", fmt.Sprint(tc.wantParams), fmt.Sprint(params))
+			}
+		})
+	}
+}
@@ -34,6 +34,14 @@ func like(left, right string) (string, error) {
 	return fmt.Sprintf("%s SIMILAR TO %s", left, right), nil
 }
 
+func exists(left, right string) (string, error) {
+	return fmt.Sprintf("%s IS NOT NULL", left), nil
+}
+
+func isNull(left, right string) (string, error) {
+	return fmt.Sprintf("%s IS NULL", left), nil
+}
+
 func inFn(left, right string) (string, error) {
 	return fmt.Sprintf("%s IN %s", left, right), nil
 }
@@ -59,101 +67,92 @@ func lessEq(left, right string) (string, error) {
 }
 
 // rang is more complicated than the others because it has to handle inclusive and exclusive ranges,
-// number and string ranges, and ranges that only have one bound
+// number and string ranges, and ranges that only have one bound. The right side is rendered in interval
+// notation so each side can be inclusive [ ] or exclusive ( ) on its own.
 func rang(left, right string) (string, error) {
-	inclusive := true
-	if right[0] == '(' && right[len(right)-1] == ')' {
-		inclusive = false
+	rawMin, rawMax, minInclusive, maxInclusive, err := rangeBounds(right)
+	if err != nil {
+		return "", err
 	}
 
-	stripped := right[1 : len(right)-1]
-	rangeSlice := strings.Split(stripped, ",")
-
-	if len(rangeSlice) != 2 {
-		return "", fmt.Errorf("the BETWEEN operator needs a two item list in the right hand side, have %s", right)
+	_, _, err = toInts(rawMin, rawMax)
+	if err == nil {
+		return rangeComparisons(left, rawMin, rawMax, minInclusive, maxInclusive, func(bound string) string {
+			i, _ := strconv.Atoi(bound)
+			return fmt.Sprintf("%d", i)
+		})
 	}
 
-	rawMin := strings.Trim(rangeSlice[0], " ")
-	rawMax := strings.Trim(rangeSlice[1], " ")
-
-	iMin, iMax, err := toInts(rawMin, rawMax)
+	_, _, err = toFloats(rawMin, rawMax)
 	if err == nil {
-		if rawMin == "*" {
-			if inclusive {
-				return fmt.Sprintf("%s <= %d", left, iMax), nil
-			}
-			return fmt.Sprintf("%s < %d", left, iMax), nil
-		}
-
-		if rawMax == "*" {
-			if inclusive {
-				return fmt.Sprintf("%s >= %d", left, iMin), nil
-			}
-			return fmt.Sprintf("%s > %d", left, iMin), nil
-		}
-
-		if inclusive {
-			return fmt.Sprintf("%s >= %d AND %s <= %d",
-					left,
-					iMin,
-					left,
-					iMax,
-				),
-				nil
-		}
-
-		return fmt.Sprintf("%s > %d AND %s < %d",
-				left,
-				iMin,
-				left,
-				iMax,
-			),
-			nil
-	}
-
-	fMin, fMax, err := toFloats(rawMin, rawMax)
-	if err == nil {
-		if rawMin == "*" {
-			if inclusive {
-				return fmt.Sprintf("%s <= %.2f", left, fMax), nil
-			}
-			return fmt.Sprintf("%s < %.2f", left, fMax), nil
-		}
-
-		if rawMax == "*" {
-			if inclusive {
-				return fmt.Sprintf("%s >= %.2f", left, fMin), nil
-			}
-			return fmt.Sprintf("%s > %.2f", left, fMin), nil
-		}
-
-		if inclusive {
-			return fmt.Sprintf("%s >= %.2f AND %s <= %.2f",
-					left,
-					fMin,
-					left,
-					fMax,
-				),
-				nil
-		}
-
-		return fmt.Sprintf("%s > %.2f AND %s < %.2f",
-				left,
-				fMin,
-				left,
-				fMax,
-			),
-			nil
+		return rangeComparisons(left, rawMin, rawMax, minInclusive, maxInclusive, func(bound string) string {
+			f, _ := strconv.ParseFloat(bound, 64)
+			return fmt.Sprintf("%.2f", f)
+		})
+	}
+
+	// BETWEEN can't express a range that only includes one of its bounds
+	if minInclusive != maxInclusive {
+		return rangeComparisons(left, rawMin, rawMax, minInclusive, maxInclusive, func(bound string) string {
+			return fmt.Sprintf("'%s'", bound)
+		})
 	}
 
 	return fmt.Sprintf(`%s BETWEEN '%s' AND '%s'`,
 			left,
-			strings.Trim(rangeSlice[0], " "),
-			strings.Trim(rangeSlice[1], " "),
+			rawMin,
+			rawMax,
 		),
 		nil
 }
 
+// rangeBounds splits a range rendered in interval notation into its bounds and inclusivity
+func rangeBounds(right string) (rawMin, rawMax string, minInclusive, maxInclusive bool, err error) {
+	if len(right) < 2 {
+		return "", "", false, false, fmt.Errorf("the RANGE operator needs a two item list in the right hand side, have %s", right)
+	}
+
+	minInclusive = right[0] != '('
+	maxInclusive = right[len(right)-1] != ')'
+
+	rangeSlice := strings.Split(right[1:len(right)-1], ",")
+	if len(rangeSlice) != 2 {
+		return "", "", false, false, fmt.Errorf("the RANGE operator needs a two item list in the right hand side, have %s", right)
+	}
+
+	return strings.Trim(rangeSlice[0], " "), strings.Trim(rangeSlice[1], " "), minInclusive, maxInclusive, nil
+}
+
+// rangeComparisons renders each bound of a range as a comparison, skipping unbounded (*) sides.
+// The format function renders the raw bound as a sql value.
+func rangeComparisons(
+	left, rawMin, rawMax string,
+	minInclusive, maxInclusive bool,
+	format func(bound string) string,
+) (string, error) {
+	minOp, maxOp := ">", "<"
+	if minInclusive {
+		minOp = ">="
+	}
+	if maxInclusive {
+		maxOp = "<="
+	}
+
+	clauses := []string{}
+	if rawMin != "*" {
+		clauses = append(clauses, fmt.Sprintf("%s %s %s", left, minOp, format(rawMin)))
+	}
+	if rawMax != "*" {
+		clauses = append(clauses, fmt.Sprintf("%s %s %s", left, maxOp, format(rawMax)))
+	}
+
+	if len(clauses) == 0 {
+		return "", fmt.Errorf("range on %s must have at least one bound", left)
+	}
+
+	return strings.Join(clauses, " AND "), nil
+}
+
 func basicCompound(op expr.Operator) RenderFN {
 	return func(left, right string) (string, error) {
 		return fmt.Sprintf("(%s) %s (%s)", left, op, right), nil
@@ -0,0 +1,123 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// SQLiteDriver transforms a parsed lucene expression to a sqlite filter.
+type SQLiteDriver struct {
+	Base
+}
+
+// NewSQLiteDriver creates a new driver that will output a parsed lucene expression as a SQLite filter.
+// Regular expressions are rendered with the REGEXP operator which requires a regexp function to
+// be registered with the sqlite connection.
+func NewSQLiteDriver(opts ...Option) SQLiteDriver {
+	fns := map[expr.Operator]RenderFN{
+		expr.Literal: literal,
+		expr.Like:    sqliteLike,
+		expr.Range:   sqliteRang,
+	}
+
+	for op, sharedFN := range Shared {
+		_, found := fns[op]
+		if !found {
+			fns[op] = sharedFN
+		}
+	}
+
+	b := Base{
+		renderFNs:   fns,
+		quoteColumn: doubleQuoteColumn,
+		placeholder: questionPlaceholder,
+		matchFNs: map[expr.Operator]MatchFN{
+			expr.Wild:   sqliteGlobParam,
+			expr.Regexp: sqliteRegexpParam,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(&b)
+	}
+
+	return SQLiteDriver{b}
+}
+
+// sqliteLike uses GLOB for wildcards since it shares the * and ? syntax with lucene and
+// is case sensitive, and REGEXP for regular expressions.
+func sqliteLike(left, right string) (string, error) {
+	if len(right) >= 4 && right[1] == '/' && right[len(right)-2] == '/' {
+		return fmt.Sprintf("%s REGEXP '%s'", left, right[2:len(right)-2]), nil
+	}
+
+	if len(right) >= 2 && right[0] == '\'' && right[len(right)-1] == '\'' {
+		right = fmt.Sprintf("'%s'", globPattern(right[1:len(right)-1]))
+	}
+	return fmt.Sprintf("%s GLOB %s", left, right), nil
+}
+
+// sqliteRang renders every range as comparisons. Unlike BETWEEN this respects exclusive
+// string ranges.
+func sqliteRang(left, right string) (string, error) {
+	rawMin, rawMax, minInclusive, maxInclusive, err := rangeBounds(right)
+	if err != nil {
+		return "", err
+	}
+
+	// numbers are already rendered as comparisons by the shared range
+	if _, _, err := toFloats(rawMin, rawMax); err == nil {
+		return rang(left, right)
+	}
+
+	return rangeComparisons(left, rawMin, rawMax, minInclusive, maxInclusive, func(bound string) string {
+		return fmt.Sprintf("'%s'", bound)
+	})
+}
+
+func sqliteGlobParam(column, placeholder, pattern string) (string, any, error) {
+	return fmt.Sprintf("%s GLOB %s", column, placeholder), globPattern(pattern), nil
+}
+
+func sqliteRegexpParam(column, placeholder, pattern string) (string, any, error) {
+	return fmt.Sprintf("%s REGEXP %s", column, placeholder), stripRegexpDelimiters(pattern), nil
+}
+
+// globPattern converts a lucene wildcard into a GLOB pattern. GLOB already uses * and ? so only
+// lucene escaped characters and GLOB character classes need to be handled.
+func globPattern(pattern string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			if r == '*' || r == '?' || r == '[' {
+				sb.WriteString(fmt.Sprintf("[%c]", r))
+				continue
+			}
+			sb.WriteRune(r)
+		case r == '\\':
+			escaped = true
+		case r == '[':
+			sb.WriteString("[[]")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// doubleQuoteColumn quotes a column in double quotes if it contains a space. Columns
+// that are already quoted are left alone.
+func doubleQuoteColumn(column string) string {
+	if len(column) >= 2 && column[0] == '"' && column[len(column)-1] == '"' {
+		return column
+	}
+	if !strings.Contains(column, " ") {
+		return column
+	}
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(column, `"`, `""`))
+}
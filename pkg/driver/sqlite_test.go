@@ -0,0 +1,144 @@
+package driver
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+func TestSQLiteDriver(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		want  string
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input: expr.Eq("a", 5),
+			want:  "a = 5",
+		},
+		"simple_and": {
+			input: expr.AND(expr.Eq("a", 5), expr.Eq("b", "foo")),
+			want:  `(a = 5) AND (b = 'foo')`,
+		},
+		"simple_not": {
+			input: expr.NOT(expr.Eq("a", 1)),
+			want:  `NOT(a = 1)`,
+		},
+		"wildcard_glob": {
+			input: expr.LIKE("a", "b*z?"),
+			want:  `a GLOB 'b*z?'`,
+		},
+		"wildcard_glob_escapes": {
+			input: expr.LIKE("a", expr.WILD(`[x]\*y*`)),
+			want:  `a GLOB '[[]x][*]y*'`,
+		},
+		"regexp_like": {
+			input: expr.LIKE("a", expr.REGEXP("/b*ar/")),
+			want:  `a REGEXP 'b*ar'`,
+		},
+		"string_range": {
+			input: expr.Rang("a", "bar", "foo", true),
+			want:  `a >= 'bar' AND a <= 'foo'`,
+		},
+		"string_range_exclusive": {
+			input: expr.Rang("a", "bar", "foo", false),
+			want:  `a > 'bar' AND a < 'foo'`,
+		},
+		"string_range_unbound": {
+			input: expr.Rang("a", "bar", "*", false),
+			want:  `a > 'bar'`,
+		},
+		"string_range_half_open": {
+			input: expr.RangBounds("a", "bar", "foo", true, false),
+			want:  `a >= 'bar' AND a < 'foo'`,
+		},
+		"int_range": {
+			input: expr.Rang("a", 1, 10, true),
+			want:  `a >= 1 AND a <= 10`,
+		},
+		"lt_range": {
+			input: expr.Rang("a", "*", 10, false),
+			want:  `a < 10`,
+		},
+		"in_list": {
+			input: expr.IN("a", expr.LIST(expr.Lit("b"), expr.Lit("c"))),
+			want:  `a IN ('b', 'c')`,
+		},
+		"must_ignored": {
+			input: expr.MUST(expr.Eq("a", 1)),
+			want:  `a = 1`,
+		},
+		"space_in_fieldname": {
+			input: expr.Eq("a b", 1),
+			want:  `"a b" = 1`,
+		},
+		"quoted_column_name": {
+			input: expr.Eq(`"foobar"`, 1),
+			want:  `"foobar" = 1`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewSQLiteDriver().Render(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSQLiteDriverParams(t *testing.T) {
+	type tc struct {
+		input      *expr.Expression
+		want       string
+		wantParams []any
+	}
+
+	tcs := map[string]tc{
+		"simple_equals": {
+			input:      expr.Eq("a", "O'Brien"),
+			want:       "a = ?",
+			wantParams: []any{"O'Brien"},
+		},
+		"wildcard_glob": {
+			input:      expr.LIKE("a", "b*"),
+			want:       `a GLOB ?`,
+			wantParams: []any{`b*`},
+		},
+		"regexp_like": {
+			input:      expr.LIKE("a", expr.REGEXP("/b*ar/")),
+			want:       `a REGEXP ?`,
+			wantParams: []any{"b*ar"},
+		},
+		"range": {
+			input:      expr.Rang("a", "bar", "*", true),
+			want:       `a >= ?`,
+			wantParams: []any{"bar"},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, params, err := NewSQLiteDriver().RenderParam(tc.input)
+			if err != nil {
+				t.Fatalf("got an unexpected error when rendering: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf(errTemplate, "generated sql does not match", tc.want, got)
+			}
+
+			if !reflect.DeepEqual(tc.wantParams, params) {
+				t.Fatalf(errTemplate, "generated params do not match", fmt.Sprint(tc.wantParams), fmt.Sprint(params))
+			}
+		})
+	}
+}
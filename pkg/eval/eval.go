@@ -0,0 +1,655 @@
+package eval
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// Predicate reports whether a record matches a compiled expression. The record can be a
+// map with string keys or a struct (or a pointer to either).
+type Predicate func(record any) bool
+
+// Compile compiles an expression into a predicate that can be applied to in memory records.
+// Any work that can be done up front, like compiling regular expressions, is done here so the
+// returned predicate is cheap to call repeatedly.
+//
+// Fields are resolved against maps by key and against structs using the `lucene` struct tag,
+// falling back to the `json` tag and then the field name. Dotted fields (a.b.c) are resolved
+// through nested maps and structs. Relative dates like now-7d are resolved against the current time,
+// or the clock passed with WithClock, when the expression is compiled.
+func Compile(e *expr.Expression, opts ...Option) (p Predicate, err error) {
+	o := options{now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return compile(expr.ResolveDates(e, o.now()))
+}
+
+// Option configures how an expression is compiled
+type Option func(*options)
+
+type options struct {
+	now func() time.Time
+}
+
+// WithClock sets the clock that relative dates like now-7d/d are resolved against when an expression
+// is compiled. This is mostly useful for tests.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+func compile(e *expr.Expression) (p Predicate, err error) {
+	if e == nil {
+		return func(any) bool { return true }, nil
+	}
+
+	fn, found := compilers[e.Op]
+	if !found {
+		return p, fmt.Errorf("unable to evaluate operator [%s]", e.Op)
+	}
+	return fn(e)
+}
+
+// Match is a helper that compiles the expression and applies it to a single record.
+func Match(e *expr.Expression, record any, opts ...Option) (bool, error) {
+	p, err := Compile(e, opts...)
+	if err != nil {
+		return false, err
+	}
+	return p(record), nil
+}
+
+type compiler func(e *expr.Expression) (Predicate, error)
+
+var compilers map[expr.Operator]compiler
+
+func init() {
+	// this is initialized in init because the compound compilers recurse through compile
+	compilers = map[expr.Operator]compiler{
+		expr.And:       compileAnd,
+		expr.Or:        compileOr,
+		expr.Not:       compileNot,
+		expr.MustNot:   compileNot,
+		expr.Must:      compileWrapped,
+		expr.Boost:     compileWrapped, // there is no scoring in memory so boosts don't matter
+		expr.Equals:    compileEquals,
+		expr.Like:      compileLike,
+		expr.In:        compileIn,
+		expr.Range:     compileRange,
+		expr.Greater:   compileCompare(func(c int) bool { return c > 0 }),
+		expr.GreaterEq: compileCompare(func(c int) bool { return c >= 0 }),
+		expr.Less:      compileCompare(func(c int) bool { return c < 0 }),
+		expr.LessEq:    compileCompare(func(c int) bool { return c <= 0 }),
+		expr.Fuzzy:     compileFuzzy,
+		expr.Proximity: compileProximity,
+		expr.Exists:    compileExists,
+		expr.Literal:   compileAnyField,
+		expr.Wild:      compileAnyField,
+		expr.Regexp:    compileAnyField,
+	}
+}
+
+func compileAnd(e *expr.Expression) (p Predicate, err error) {
+	left, right, err := compileSides(e)
+	if err != nil {
+		return p, err
+	}
+	return func(record any) bool {
+		return left(record) && right(record)
+	}, nil
+}
+
+func compileOr(e *expr.Expression) (p Predicate, err error) {
+	left, right, err := compileSides(e)
+	if err != nil {
+		return p, err
+	}
+	return func(record any) bool {
+		return left(record) || right(record)
+	}, nil
+}
+
+func compileNot(e *expr.Expression) (p Predicate, err error) {
+	sub, err := compileSub(e.Left)
+	if err != nil {
+		return p, err
+	}
+	return func(record any) bool {
+		return !sub(record)
+	}, nil
+}
+
+func compileWrapped(e *expr.Expression) (p Predicate, err error) {
+	return compileSub(e.Left)
+}
+
+func compileEquals(e *expr.Expression) (p Predicate, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return p, err
+	}
+
+	right, ok := e.Right.(*expr.Expression)
+	if ok && right.Op == expr.Fuzzy {
+		return compileFuzzy(expr.FUZZY(expr.Eq(e.Left, right.Left), right.FuzzyDistance()))
+	}
+
+	m, err := valueMatcher(e.Right)
+	if err != nil {
+		return p, err
+	}
+	return fieldPredicate(field, m), nil
+}
+
+func compileLike(e *expr.Expression) (p Predicate, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return p, err
+	}
+
+	m, err := valueMatcher(e.Right)
+	if err != nil {
+		return p, err
+	}
+	return fieldPredicate(field, m), nil
+}
+
+func compileIn(e *expr.Expression) (p Predicate, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return p, err
+	}
+
+	list, ok := e.Right.(*expr.Expression)
+	if !ok || list.Op != expr.List {
+		return p, fmt.Errorf("IN must have a list on the right side, got %T", e.Right)
+	}
+
+	vals, ok := list.Left.([]*expr.Expression)
+	if !ok {
+		return p, fmt.Errorf("LIST must contain expressions, got %T", list.Left)
+	}
+
+	matchers := []matcher{}
+	for _, v := range vals {
+		m, err := valueMatcher(v)
+		if err != nil {
+			return p, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	return fieldPredicate(field, func(val any) bool {
+		for _, m := range matchers {
+			if m(val) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func compileRange(e *expr.Expression) (p Predicate, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return p, err
+	}
+
+	boundary, ok := e.Right.(*expr.RangeBoundary)
+	if !ok {
+		return p, fmt.Errorf("RANGE must have a range boundary on the right side, got %T", e.Right)
+	}
+
+	min, minUnbound, err := boundValue(boundary.Min)
+	if err != nil {
+		return p, err
+	}
+	max, maxUnbound, err := boundValue(boundary.Max)
+	if err != nil {
+		return p, err
+	}
+
+	return fieldPredicate(field, func(val any) bool {
+		if !minUnbound {
+			c, ok := compare(val, min)
+			if !ok || c < 0 || (c == 0 && !boundary.MinInclusive) {
+				return false
+			}
+		}
+		if !maxUnbound {
+			c, ok := compare(val, max)
+			if !ok || c > 0 || (c == 0 && !boundary.MaxInclusive) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+func compileCompare(accept func(c int) bool) compiler {
+	return func(e *expr.Expression) (p Predicate, err error) {
+		field, err := fieldOf(e.Left)
+		if err != nil {
+			return p, err
+		}
+
+		want, err := literalOf(e.Right)
+		if err != nil {
+			return p, err
+		}
+
+		return fieldPredicate(field, func(val any) bool {
+			c, ok := compare(val, want)
+			return ok && accept(c)
+		}), nil
+	}
+}
+
+// compileFuzzy matches values within the fuzzy edit distance of the term. A fuzzy term
+// without a field matches if any value in the record is close enough.
+func compileFuzzy(e *expr.Expression) (p Predicate, err error) {
+	sub, ok := e.Left.(*expr.Expression)
+	if !ok {
+		return p, fmt.Errorf("FUZZY must wrap an expression, got %T", e.Left)
+	}
+
+	distance := e.FuzzyDistance()
+	fuzzy := func(term any) matcher {
+		t := fmt.Sprintf("%v", term)
+		return func(val any) bool {
+			return levenshtein(toString(val), t) <= distance
+		}
+	}
+
+	switch sub.Op {
+	case expr.Literal:
+		return anyFieldPredicate(fuzzy(sub.Left)), nil
+	case expr.Equals:
+		field, err := fieldOf(sub.Left)
+		if err != nil {
+			return p, err
+		}
+		term, err := literalOf(sub.Right)
+		if err != nil {
+			return p, err
+		}
+		return fieldPredicate(field, fuzzy(term)), nil
+	}
+	return p, fmt.Errorf("unable to evaluate FUZZY over [%s]", sub.Op)
+}
+
+// compileProximity matches values that contain the terms of the phrase in order with at most
+// the proximity distance of extra words between them.
+func compileProximity(e *expr.Expression) (p Predicate, err error) {
+	sub, ok := e.Left.(*expr.Expression)
+	if !ok {
+		return p, fmt.Errorf("PROXIMITY must wrap an expression, got %T", e.Left)
+	}
+
+	slop := e.ProximityDistance()
+	near := func(phrase any) matcher {
+		terms := strings.Fields(toString(phrase))
+		return func(val any) bool {
+			return withinSlop(strings.Fields(toString(val)), terms, slop)
+		}
+	}
+
+	switch sub.Op {
+	case expr.Literal:
+		return anyFieldPredicate(near(sub.Left)), nil
+	case expr.Equals:
+		field, err := fieldOf(sub.Left)
+		if err != nil {
+			return p, err
+		}
+		phrase, err := literalOf(sub.Right)
+		if err != nil {
+			return p, err
+		}
+		return fieldPredicate(field, near(phrase)), nil
+	}
+	return p, fmt.Errorf("unable to evaluate PROXIMITY over [%s]", sub.Op)
+}
+
+// compileExists matches records that have a non nil value for the field
+func compileExists(e *expr.Expression) (p Predicate, err error) {
+	field, err := fieldOf(e.Left)
+	if err != nil {
+		return p, err
+	}
+
+	return func(record any) bool {
+		_, found := Lookup(record, field)
+		return found
+	}, nil
+}
+
+// compileAnyField compiles a term without a field. It matches if any value in the record matches.
+func compileAnyField(e *expr.Expression) (p Predicate, err error) {
+	m, err := valueMatcher(e)
+	if err != nil {
+		return p, err
+	}
+	return anyFieldPredicate(m), nil
+}
+
+func compileSides(e *expr.Expression) (left, right Predicate, err error) {
+	left, err = compileSub(e.Left)
+	if err != nil {
+		return left, right, err
+	}
+	right, err = compileSub(e.Right)
+	return left, right, err
+}
+
+func compileSub(in any) (p Predicate, err error) {
+	e, ok := in.(*expr.Expression)
+	if !ok {
+		return p, fmt.Errorf("expected a sub expression, got %T", in)
+	}
+	return compile(e)
+}
+
+// matcher checks a single value pulled out of a record
+type matcher func(val any) bool
+
+// valueMatcher builds a matcher for a literal, wildcard or regexp expression
+func valueMatcher(in any) (m matcher, err error) {
+	e, ok := in.(*expr.Expression)
+	if !ok {
+		return m, fmt.Errorf("expected a literal expression, got %T", in)
+	}
+
+	switch e.Op {
+	case expr.Literal:
+		want := e.Left
+		return func(val any) bool {
+			return equal(val, want)
+		}, nil
+	case expr.Wild:
+		re, err := regexp.Compile(expr.WildcardToRegexp(fmt.Sprintf("%v", e.Left)))
+		if err != nil {
+			return m, err
+		}
+		return func(val any) bool {
+			return re.MatchString(toString(val))
+		}, nil
+	case expr.Regexp:
+		pattern := fmt.Sprintf("%v", e.Left)
+		if len(pattern) >= 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
+			pattern = pattern[1 : len(pattern)-1]
+		}
+		// lucene regular expressions always match the entire term
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+		if err != nil {
+			return m, fmt.Errorf("invalid regexp %s: %w", e.Left, err)
+		}
+		return func(val any) bool {
+			return re.MatchString(toString(val))
+		}, nil
+	}
+	return m, fmt.Errorf("unable to match values with [%s]", e.Op)
+}
+
+// fieldPredicate looks up the field in the record and applies the matcher. Multi valued
+// fields (slices and arrays) match if any of their elements match.
+func fieldPredicate(field string, m matcher) Predicate {
+	return func(record any) bool {
+		val, found := Lookup(record, field)
+		if !found {
+			return false
+		}
+		return matchAny(val, m)
+	}
+}
+
+// anyFieldPredicate matches if any leaf value anywhere in the record matches
+func anyFieldPredicate(m matcher) Predicate {
+	return func(record any) bool {
+		return anyLeaf(reflect.ValueOf(record), m)
+	}
+}
+
+func matchAny(val any, m matcher) bool {
+	v := reflect.ValueOf(val)
+	if isList(v) {
+		for i := 0; i < v.Len(); i++ {
+			if matchAny(v.Index(i).Interface(), m) {
+				return true
+			}
+		}
+		return false
+	}
+	return m(val)
+}
+
+func anyLeaf(v reflect.Value, m matcher) bool {
+	v = indirect(v)
+	if !v.IsValid() {
+		return false
+	}
+
+	switch {
+	case v.Kind() == reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if anyLeaf(iter.Value(), m) {
+				return true
+			}
+		}
+		return false
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() && anyLeaf(v.Field(i), m) {
+				return true
+			}
+		}
+		return false
+	case isList(v):
+		for i := 0; i < v.Len(); i++ {
+			if anyLeaf(v.Index(i), m) {
+				return true
+			}
+		}
+		return false
+	}
+	return m(v.Interface())
+}
+
+func fieldOf(in any) (field string, err error) {
+	val, err := literalOf(in)
+	if err != nil {
+		return field, err
+	}
+	return fmt.Sprintf("%v", val), nil
+}
+
+func literalOf(in any) (val any, err error) {
+	e, ok := in.(*expr.Expression)
+	if !ok {
+		return val, fmt.Errorf("expected a literal expression, got %T", in)
+	}
+
+	if e.Op != expr.Literal && e.Op != expr.Wild && e.Op != expr.Regexp {
+		return val, fmt.Errorf("expected a literal expression, got [%s]", e.Op)
+	}
+
+	if col, isCol := e.Left.(expr.Column); isCol {
+		return string(col), nil
+	}
+	return e.Left, nil
+}
+
+// boundValue returns the value of a range boundary and whether it is the unbounded * boundary
+func boundValue(in any) (val any, unbounded bool, err error) {
+	val, err = literalOf(in)
+	if err != nil {
+		return val, false, err
+	}
+	return val, val == "*", nil
+}
+
+// equal checks if a record value equals a literal from the query. Numbers are compared
+// numerically and everything else is compared by its string representation.
+func equal(val, want any) bool {
+	if b, isTime := want.(time.Time); isTime {
+		a, ok := toTime(val)
+		return ok && a.Equal(b)
+	}
+	if a, ok := toFloat(val); ok {
+		if b, ok := toFloat(want); ok {
+			return a == b
+		}
+	}
+	return toString(val) == toString(want)
+}
+
+// compare orders a record value against a literal from the query. Numbers are compared numerically
+// and strings lexically. It returns false if the values can't be ordered.
+func compare(val, want any) (int, bool) {
+	if b, isTime := want.(time.Time); isTime {
+		a, ok := toTime(val)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case a.Before(b):
+			return -1, true
+		case a.After(b):
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if a, ok := toFloat(val); ok {
+		if b, ok := toFloat(want); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+
+	if _, isStr := want.(string); !isStr {
+		return 0, false
+	}
+	return strings.Compare(toString(val), toString(want)), true
+}
+
+func toFloat(in any) (float64, bool) {
+	v := reflect.ValueOf(in)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toTime converts a record value into a time. Strings are parsed as ISO-8601 dates.
+func toTime(in any) (time.Time, bool) {
+	switch v := in.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	case string:
+		d, err := expr.ParseDate(v)
+		if err != nil || d.IsRelative() {
+			return time.Time{}, false
+		}
+		return d.Resolve(time.Time{}), true
+	}
+	return time.Time{}, false
+}
+
+func toString(in any) string {
+	if s, isStr := in.(string); isStr {
+		return s
+	}
+	if s, isStringer := in.(fmt.Stringer); isStringer {
+		return s.String()
+	}
+	return fmt.Sprintf("%v", in)
+}
+
+// withinSlop checks if the terms appear in order in the words with at most slop words
+// in between them. The closest following match is taken for each term since that
+// keeps the span for a given starting word as small as possible.
+func withinSlop(words, terms []string, slop int) bool {
+	if len(terms) == 0 {
+		return true
+	}
+
+	for start, w := range words {
+		if w != terms[0] {
+			continue
+		}
+
+		pos, matched := start, 1
+		for i := start + 1; i < len(words) && matched < len(terms); i++ {
+			if words[i] == terms[matched] {
+				pos = i
+				matched++
+			}
+		}
+		if matched == len(terms) && pos-start-(len(terms)-1) <= slop {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein computes the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
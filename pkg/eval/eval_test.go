@@ -0,0 +1,345 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+type apple struct {
+	Color   string   `json:"color"`
+	Kind    string   `lucene:"type" json:"kind"`
+	Age     int      `json:"age_in_months"`
+	Weight  float64  `json:"weight"`
+	Tags    []string `json:"tags"`
+	Orchard *orchard `json:"orchard"`
+	secret  string
+}
+
+type orchard struct {
+	Name string `json:"name"`
+}
+
+func TestEval(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		want  bool
+	}
+
+	record := map[string]any{
+		"color":         "red",
+		"type":          "honey crisp",
+		"age_in_months": 4,
+		"weight":        0.25,
+		"tags":          []any{"fresh", "local"},
+		"orchard":       map[string]any{"name": "north"},
+		"dotted.key":    "yes",
+		"description":   "a sweet and crisp red apple",
+		"missing":       nil,
+		"picked":        "2024-01-15T09:00:00Z",
+	}
+	now := time.Date(2024, 3, 15, 13, 30, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	tcs := map[string]tc{
+		"equals": {
+			input: expr.Eq("color", "red"),
+			want:  true,
+		},
+		"equals_mismatch": {
+			input: expr.Eq("color", "green"),
+			want:  false,
+		},
+		"date_comparison": {
+			input: expr.GREATER("picked", "2024-01-01"),
+			want:  true,
+		},
+		"date_math_range": {
+			input: expr.Rang("picked", "2024-01-15||/d", "now", true),
+			want:  true,
+		},
+		"date_math_range_mismatch": {
+			input: expr.Rang("picked", "now-1d", "now", true),
+			want:  false,
+		},
+		"date_math_relative_to_clock": {
+			input: expr.Rang("picked", "now-61d", "now", true),
+			want:  true,
+		},
+		"date_math_relative_to_clock_mismatch": {
+			input: expr.Rang("picked", "now-60d", "now", true),
+			want:  false,
+		},
+		"equals_missing_field": {
+			input: expr.Eq("shape", "round"),
+			want:  false,
+		},
+		"equals_nil_field": {
+			input: expr.Eq("missing", "round"),
+			want:  false,
+		},
+		"equals_number": {
+			input: expr.Eq("age_in_months", 4),
+			want:  true,
+		},
+		"equals_multi_valued": {
+			input: expr.Eq("tags", "local"),
+			want:  true,
+		},
+		"equals_nested": {
+			input: expr.Eq("orchard.name", "north"),
+			want:  true,
+		},
+		"equals_dotted_key": {
+			input: expr.Eq("dotted.key", "yes"),
+			want:  true,
+		},
+		"and": {
+			input: expr.AND(expr.Eq("color", "red"), expr.Eq("type", "honey crisp")),
+			want:  true,
+		},
+		"and_mismatch": {
+			input: expr.AND(expr.Eq("color", "red"), expr.Eq("type", "gala")),
+			want:  false,
+		},
+		"or": {
+			input: expr.OR(expr.Eq("color", "green"), expr.Eq("type", "honey crisp")),
+			want:  true,
+		},
+		"not": {
+			input: expr.NOT(expr.Eq("color", "green")),
+			want:  true,
+		},
+		"must_not": {
+			input: expr.MUSTNOT(expr.Eq("color", "red")),
+			want:  false,
+		},
+		"must": {
+			input: expr.MUST(expr.Eq("color", "red")),
+			want:  true,
+		},
+		"boost_ignored": {
+			input: expr.BOOST(expr.Eq("color", "red"), 2),
+			want:  true,
+		},
+		"in": {
+			input: expr.IN("color", expr.LIST(expr.Lit("green"), expr.Lit("red"))),
+			want:  true,
+		},
+		"in_mismatch": {
+			input: expr.IN("color", expr.LIST(expr.Lit("green"), expr.Lit("yellow"))),
+			want:  false,
+		},
+		"inclusive_range": {
+			input: expr.Rang("age_in_months", 1, 4, true),
+			want:  true,
+		},
+		"exclusive_range": {
+			input: expr.Rang("age_in_months", 1, 4, false),
+			want:  false,
+		},
+		"exists": {
+			input: expr.EXISTS("color"),
+			want:  true,
+		},
+		"exists_missing_field": {
+			input: expr.EXISTS("shape"),
+			want:  false,
+		},
+		"exists_nil_field": {
+			input: expr.EXISTS("missing"),
+			want:  false,
+		},
+		"not_exists_nested": {
+			input: expr.NOT(expr.EXISTS("orchard.name")),
+			want:  false,
+		},
+		"half_open_range_includes_min": {
+			input: expr.RangBounds("age_in_months", 4, 10, true, false),
+			want:  true,
+		},
+		"half_open_range_excludes_max": {
+			input: expr.RangBounds("age_in_months", 1, 4, true, false),
+			want:  false,
+		},
+		"unbound_range": {
+			input: expr.Rang("weight", "*", 0.5, false),
+			want:  true,
+		},
+		"string_range": {
+			input: expr.Rang("color", "a", "s", true),
+			want:  true,
+		},
+		"greater": {
+			input: expr.GREATER("age_in_months", 3),
+			want:  true,
+		},
+		"greater_eq": {
+			input: expr.GREATEREQ("age_in_months", 5),
+			want:  false,
+		},
+		"less": {
+			input: expr.LESS("weight", 0.3),
+			want:  true,
+		},
+		"less_eq": {
+			input: expr.LESSEQ("age_in_months", 4),
+			want:  true,
+		},
+		"wildcard": {
+			input: expr.LIKE("type", "honey*"),
+			want:  true,
+		},
+		"wildcard_single_char": {
+			input: expr.LIKE("color", "r?d"),
+			want:  true,
+		},
+		"wildcard_mismatch": {
+			input: expr.LIKE("color", "re"),
+			want:  false,
+		},
+		"regexp": {
+			input: expr.LIKE("type", expr.REGEXP("/honey [a-z]+/")),
+			want:  true,
+		},
+		"regexp_is_anchored": {
+			input: expr.LIKE("type", expr.REGEXP("/honey/")),
+			want:  false,
+		},
+		"fuzzy": {
+			input: expr.FUZZY(expr.Eq("color", "rad"), 1),
+			want:  true,
+		},
+		"fuzzy_too_far": {
+			input: expr.FUZZY(expr.Eq("color", "blue"), 2),
+			want:  false,
+		},
+		"fuzzy_value": {
+			input: expr.Eq("color", expr.FUZZY("reed", 1)),
+			want:  true,
+		},
+		"fuzzy_literal": {
+			input: expr.FUZZY("nort", 1),
+			want:  true,
+		},
+		"proximity": {
+			input: expr.PROXIMITY(expr.Eq("description", "sweet crisp"), 2),
+			want:  true,
+		},
+		"proximity_exact": {
+			input: expr.PROXIMITY(expr.Eq("description", "crisp red apple"), 0),
+			want:  true,
+		},
+		"proximity_too_far": {
+			input: expr.PROXIMITY(expr.Eq("description", "sweet apple"), 2),
+			want:  false,
+		},
+		"proximity_out_of_order": {
+			input: expr.PROXIMITY(expr.Eq("description", "apple crisp"), 3),
+			want:  false,
+		},
+		"proximity_literal": {
+			input: expr.PROXIMITY(expr.Lit("sweet red"), 3),
+			want:  true,
+		},
+		"bare_literal": {
+			input: expr.Lit("local"),
+			want:  true,
+		},
+		"bare_wildcard": {
+			input: expr.WILD("nor*"),
+			want:  true,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := Match(tc.input, record, clock)
+			if err != nil {
+				t.Fatalf("got an unexpected error when evaluating: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf("wanted %t but got %t for %s", tc.want, got, tc.input)
+			}
+		})
+	}
+}
+
+func TestEvalStruct(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		want  bool
+	}
+
+	record := &apple{
+		Color:   "red",
+		Kind:    "gala",
+		Age:     4,
+		Weight:  0.25,
+		Tags:    []string{"fresh"},
+		Orchard: &orchard{Name: "north"},
+		secret:  "hidden",
+	}
+
+	tcs := map[string]tc{
+		"json_tag": {
+			input: expr.Eq("color", "red"),
+			want:  true,
+		},
+		"lucene_tag_wins": {
+			input: expr.Eq("type", "gala"),
+			want:  true,
+		},
+		"field_name": {
+			input: expr.Eq("Weight", 0.25),
+			want:  true,
+		},
+		"slice_field": {
+			input: expr.Eq("tags", "fresh"),
+			want:  true,
+		},
+		"nested_pointer": {
+			input: expr.Eq("orchard.name", "north"),
+			want:  true,
+		},
+		"range": {
+			input: expr.Rang("age_in_months", 1, 10, true),
+			want:  true,
+		},
+		"unexported_ignored": {
+			input: expr.Eq("secret", "hidden"),
+			want:  false,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got, err := Match(tc.input, record)
+			if err != nil {
+				t.Fatalf("got an unexpected error when evaluating: %v", err)
+			}
+
+			if tc.want != got {
+				t.Fatalf("wanted %t but got %t for %s", tc.want, got, tc.input)
+			}
+		})
+	}
+}
+
+func TestEvalCompileFailure(t *testing.T) {
+	tcs := map[string]*expr.Expression{
+		"invalid_regexp": expr.LIKE("a", expr.REGEXP("/a(b/")),
+		"fuzzy_compound": expr.FUZZY(expr.AND(expr.Eq("a", 1), expr.Eq("b", 2)), 1),
+	}
+
+	for name, input := range tcs {
+		t.Run(name, func(t *testing.T) {
+			_, err := Compile(input)
+			if err == nil {
+				t.Fatalf("expected error but did not get one")
+			}
+		})
+	}
+}
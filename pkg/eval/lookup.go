@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Lookup resolves a field in a record. Maps are resolved by key and structs are resolved using the
+// `lucene` struct tag, then the `json` struct tag, then the field name (case insensitive). If the
+// field is not found directly and contains dots it is resolved as a path through nested records.
+func Lookup(record any, field string) (val any, found bool) {
+	v, found := lookup(reflect.ValueOf(record), field)
+	if !found {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func lookup(v reflect.Value, field string) (out reflect.Value, found bool) {
+	v = indirect(v)
+	if !v.IsValid() {
+		return out, false
+	}
+
+	out, found = child(v, field)
+	if found {
+		return out, true
+	}
+
+	// check each split point so keys that contain dots themselves still resolve
+	for i := strings.Index(field, "."); i >= 0; {
+		parent, found := child(v, field[:i])
+		if found {
+			out, found = lookup(parent, field[i+1:])
+			if found {
+				return out, true
+			}
+		}
+
+		next := strings.Index(field[i+1:], ".")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return out, false
+}
+
+// child resolves a single key in a map or struct
+func child(v reflect.Value, key string) (out reflect.Value, found bool) {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return out, false
+		}
+		out = indirect(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())))
+	case reflect.Struct:
+		idx, found := structField(v.Type(), key)
+		if !found {
+			return out, false
+		}
+		out = indirect(v.Field(idx))
+	}
+
+	// nil values are treated the same as missing values
+	return out, out.IsValid()
+}
+
+// structField finds the index of the struct field matching the key
+func structField(t reflect.Type, key string) (idx int, found bool) {
+	byName := -1
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		for _, tag := range []string{"lucene", "json"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == key {
+				return i, true
+			}
+		}
+
+		if byName < 0 && strings.EqualFold(f.Name, key) {
+			byName = i
+		}
+	}
+	return byName, byName >= 0
+}
+
+// indirect dereferences pointers and interfaces until it reaches a concrete value
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isList checks if the value is a slice or array that should be treated as a multi valued field
+func isList(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+	// byte slices are values not lists
+	return v.Type().Elem().Kind() != reflect.Uint8
+}
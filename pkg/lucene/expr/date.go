@@ -0,0 +1,327 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date is a date literal. It is either an absolute ISO-8601 date like 2024-01-01T10:00:00Z or
+// Elasticsearch style date math like now-7d/d that is resolved against a clock when the query
+// is rendered. Date math starts with now (or an absolute date followed by ||) and is followed by
+// any number of +N<unit>, -N<unit> and /<unit> (rounding) operations where the units are
+// y, M, w, d, h (or H), m and s.
+type Date struct {
+	raw    string
+	anchor time.Time // the zero time means the date is relative to now
+	ops    []dateOp
+}
+
+type dateOp struct {
+	op   byte // one of +, - or /
+	n    int
+	unit byte
+}
+
+// dateLayouts are the ISO-8601 layouts we accept for absolute dates. Dates without a timezone are UTC.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseDate parses an ISO-8601 date or date math expression into a Date
+func ParseDate(s string) (d Date, err error) {
+	d.raw = s
+
+	rest := ""
+	switch {
+	case strings.HasPrefix(s, "now"):
+		rest = s[len("now"):]
+	default:
+		anchor := s
+		if idx := strings.Index(s, "||"); idx >= 0 {
+			anchor, rest = s[:idx], s[idx+len("||"):]
+		}
+		d.anchor, err = parseAbsoluteDate(anchor)
+		if err != nil {
+			return d, err
+		}
+	}
+
+	d.ops, err = parseDateMath(rest)
+	if err != nil {
+		return d, fmt.Errorf("invalid date math in [%s]: %w", s, err)
+	}
+	return d, nil
+}
+
+func parseAbsoluteDate(s string) (t time.Time, err error) {
+	for _, layout := range dateLayouts {
+		t, err = time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return t, fmt.Errorf("[%s] is not an ISO-8601 date", s)
+}
+
+func parseDateMath(s string) (ops []dateOp, err error) {
+	for len(s) > 0 {
+		op := dateOp{op: s[0], n: 1}
+		s = s[1:]
+
+		switch op.op {
+		case '+', '-':
+			digits := 0
+			for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+				digits++
+			}
+			if digits == 0 {
+				return ops, fmt.Errorf("expected a number after %c", op.op)
+			}
+			op.n, err = strconv.Atoi(s[:digits])
+			if err != nil {
+				return ops, err
+			}
+			s = s[digits:]
+		case '/':
+		default:
+			return ops, fmt.Errorf("unexpected character %c", op.op)
+		}
+
+		if len(s) == 0 || !strings.ContainsRune("yMwdhHms", rune(s[0])) {
+			return ops, fmt.Errorf("expected a unit after %c", op.op)
+		}
+		op.unit = s[0]
+		s = s[1:]
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// IsDate checks if the string is a date or date math expression
+func IsDate(s string) bool {
+	_, err := ParseDate(s)
+	return err == nil
+}
+
+// IsRelative reports whether the date is relative to now
+func (d Date) IsRelative() bool {
+	return d.anchor.IsZero()
+}
+
+// Rounded reports whether the date math rounds to a unit, e.g. now/d
+func (d Date) Rounded() bool {
+	for _, op := range d.ops {
+		if op.op == '/' {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns the time the date refers to. Relative dates are resolved against now and
+// rounding goes down to the start of the unit.
+func (d Date) Resolve(now time.Time) time.Time {
+	return d.resolve(now, false)
+}
+
+// ResolveEnd returns the end of the time the date refers to, the first instant after the rounded
+// unit. For example now/d resolves to midnight tomorrow. It is the same as Resolve if the date is
+// not rounded.
+func (d Date) ResolveEnd(now time.Time) time.Time {
+	return d.resolve(now, true)
+}
+
+func (d Date) resolve(now time.Time, end bool) time.Time {
+	t := d.anchor
+	if d.IsRelative() {
+		t = now
+	}
+	// date math is done in UTC like Elasticsearch so rounding doesn't depend on the local timezone
+	t = t.UTC()
+
+	for _, op := range d.ops {
+		switch op.op {
+		case '+':
+			t = addUnits(t, op.n, op.unit)
+		case '-':
+			t = addUnits(t, -op.n, op.unit)
+		case '/':
+			t = floorUnit(t, op.unit)
+			if end {
+				t = addUnits(t, 1, op.unit)
+			}
+		}
+	}
+	return t
+}
+
+func addUnits(t time.Time, n int, unit byte) time.Time {
+	switch unit {
+	case 'y':
+		return t.AddDate(n, 0, 0)
+	case 'M':
+		return t.AddDate(0, n, 0)
+	case 'w':
+		return t.AddDate(0, 0, 7*n)
+	case 'd':
+		return t.AddDate(0, 0, n)
+	case 'h', 'H':
+		return t.Add(time.Duration(n) * time.Hour)
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute)
+	case 's':
+		return t.Add(time.Duration(n) * time.Second)
+	}
+	return t
+}
+
+// floorUnit rounds the time down to the start of the unit in the time's location. Weeks start on monday.
+func floorUnit(t time.Time, unit byte) time.Time {
+	y, mo, d := t.Date()
+	switch unit {
+	case 'y':
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, t.Location())
+	case 'M':
+		return time.Date(y, mo, 1, 0, 0, 0, 0, t.Location())
+	case 'w':
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, mo, d-offset, 0, 0, 0, 0, t.Location())
+	case 'd':
+		return time.Date(y, mo, d, 0, 0, 0, 0, t.Location())
+	case 'h', 'H':
+		return time.Date(y, mo, d, t.Hour(), 0, 0, 0, t.Location())
+	case 'm':
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), 0, 0, t.Location())
+	case 's':
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	}
+	return t
+}
+
+// String returns the date as it was written in the query
+func (d Date) String() string {
+	return d.raw
+}
+
+// GoString is a debug print for the date type
+func (d Date) GoString() string {
+	return fmt.Sprintf("DATE(%s)", d.raw)
+}
+
+// MarshalJSON serializes the date as it was written in the query
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.raw)
+}
+
+// ResolveDates returns a copy of the expression with every date literal resolved to a time.Time
+// literal using now. Rounded dates follow the Elasticsearch rounding rules so the whole rounded unit
+// is matched, e.g. a:<=now/d includes all of today and a:now/d becomes a range over today.
+func ResolveDates(e *Expression, now time.Time) *Expression {
+	if e == nil {
+		return nil
+	}
+
+	cp := *e
+	switch e.Op {
+	case Greater, GreaterEq, Less, LessEq:
+		// a bare now is only a date when it is compared against
+		if isNow(e.Right) {
+			cp.Right = Lit(nowDate)
+			e = &cp
+		}
+	case Range:
+		if boundary, isBoundary := e.Right.(*RangeBoundary); isBoundary && (isNow(boundary.Min) || isNow(boundary.Max)) {
+			b := *boundary
+			if isNow(b.Min) {
+				b.Min = Lit(nowDate)
+			}
+			if isNow(b.Max) {
+				b.Max = Lit(nowDate)
+			}
+			cp.Right = &b
+			e = &cp
+		}
+	}
+
+	switch e.Op {
+	case Literal:
+		if d, isDate := e.Left.(Date); isDate {
+			cp.Left = d.Resolve(now)
+		}
+		return &cp
+	case Equals:
+		if d, isDate := dateOf(e.Right); isDate && d.Rounded() {
+			return RangBounds(e.Left, Lit(d.Resolve(now)), Lit(d.ResolveEnd(now)), true, false)
+		}
+	case Greater:
+		if d, isDate := dateOf(e.Right); isDate && d.Rounded() {
+			return GREATEREQ(e.Left, Lit(d.ResolveEnd(now)))
+		}
+	case LessEq:
+		if d, isDate := dateOf(e.Right); isDate && d.Rounded() {
+			return LESS(e.Left, Lit(d.ResolveEnd(now)))
+		}
+	case Range:
+		boundary, isBoundary := e.Right.(*RangeBoundary)
+		if !isBoundary {
+			return &cp
+		}
+
+		b := *boundary
+		if d, isDate := dateOf(b.Min); isDate {
+			b.Min = Lit(d.Resolve(now))
+			if d.Rounded() && !b.MinInclusive {
+				b.Min, b.MinInclusive = Lit(d.ResolveEnd(now)), true
+			}
+		}
+		if d, isDate := dateOf(b.Max); isDate {
+			b.Max = Lit(d.Resolve(now))
+			if d.Rounded() && b.MaxInclusive {
+				b.Max, b.MaxInclusive = Lit(d.ResolveEnd(now)), false
+			}
+		}
+		cp.Right = &b
+		return &cp
+	}
+
+	if sub, isExpr := e.Left.(*Expression); isExpr {
+		cp.Left = ResolveDates(sub, now)
+	}
+	if sub, isExpr := e.Right.(*Expression); isExpr {
+		cp.Right = ResolveDates(sub, now)
+	}
+	if list, isList := e.Left.([]*Expression); isList {
+		resolved := make([]*Expression, 0, len(list))
+		for _, sub := range list {
+			resolved = append(resolved, ResolveDates(sub, now))
+		}
+		cp.Left = resolved
+	}
+	return &cp
+}
+
+// nowDate is the date math for the current time
+var nowDate = Date{raw: "now"}
+
+// isNow checks if the value is the literal string now. It is kept as a string when it is parsed so
+// searching for the word now still works, but it means the current time when compared against.
+func isNow(in any) bool {
+	e, isExpr := in.(*Expression)
+	return isExpr && e.Op == Literal && e.Left == "now"
+}
+
+// dateOf returns the date wrapped by a literal expression
+func dateOf(in any) (d Date, ok bool) {
+	e, isExpr := in.(*Expression)
+	if !isExpr || e.Op != Literal {
+		return d, false
+	}
+	d, ok = e.Left.(Date)
+	return d, ok
+}
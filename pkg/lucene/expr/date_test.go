@@ -0,0 +1,116 @@
+package expr
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDate(t *testing.T) {
+	type tc struct {
+		input   string
+		want    time.Time
+		wantEnd time.Time
+	}
+
+	// a friday afternoon
+	now := time.Date(2024, time.March, 15, 13, 30, 45, 0, time.UTC)
+
+	tcs := map[string]tc{
+		"now": {
+			input:   "now",
+			want:    now,
+			wantEnd: now,
+		},
+		"date": {
+			input:   "2024-01-02",
+			want:    time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+			wantEnd: time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		"date_time_with_offset": {
+			input:   "2024-01-02T10:00:00+02:00",
+			want:    time.Date(2024, time.January, 2, 8, 0, 0, 0, time.UTC),
+			wantEnd: time.Date(2024, time.January, 2, 8, 0, 0, 0, time.UTC),
+		},
+		"subtract_days_round_to_day": {
+			input:   "now-7d/d",
+			want:    time.Date(2024, time.March, 8, 0, 0, 0, 0, time.UTC),
+			wantEnd: time.Date(2024, time.March, 9, 0, 0, 0, 0, time.UTC),
+		},
+		"add_hours": {
+			input:   "now+2h",
+			want:    time.Date(2024, time.March, 15, 15, 30, 45, 0, time.UTC),
+			wantEnd: time.Date(2024, time.March, 15, 15, 30, 45, 0, time.UTC),
+		},
+		"round_to_week": {
+			input:   "now/w",
+			want:    time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC),
+			wantEnd: time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC),
+		},
+		"anchored_math": {
+			input:   "2024-01-31||+1M/M",
+			want:    time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			d, err := ParseDate(tc.input)
+			if err != nil {
+				t.Fatalf("wanted no error parsing date, got: %v", err)
+			}
+
+			if got := d.Resolve(now); !got.Equal(tc.want) {
+				t.Fatalf(errTemplate, "resolved date doesn't match", tc.want, got)
+			}
+			if got := d.ResolveEnd(now); !got.Equal(tc.wantEnd) {
+				t.Fatalf(errTemplate, "resolved end of date doesn't match", tc.wantEnd, got)
+			}
+		})
+	}
+}
+
+func TestResolveDateRoundsInUTC(t *testing.T) {
+	// just after midnight on saturday in tokyo is still friday afternoon in UTC
+	tokyo := time.FixedZone("Asia/Tokyo", 9*60*60)
+	now := time.Date(2024, time.March, 16, 0, 30, 0, 0, tokyo)
+
+	d, err := ParseDate("now/d")
+	if err != nil {
+		t.Fatalf("wanted no error parsing date, got: %v", err)
+	}
+
+	want := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+	if got := d.Resolve(now); !got.Equal(want) {
+		t.Fatalf(errTemplate, "resolved date doesn't match", want, got)
+	}
+
+	d, err = ParseDate("2024-03-16T00:30:00+09:00||/M")
+	if err != nil {
+		t.Fatalf("wanted no error parsing date, got: %v", err)
+	}
+
+	want = time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	if got := d.Resolve(now); !got.Equal(want) {
+		t.Fatalf(errTemplate, "resolved date doesn't match", want, got)
+	}
+}
+
+func TestParseDateFailure(t *testing.T) {
+	tcs := map[string]string{
+		"not_a_date":     "tomorrow",
+		"bad_month":      "2024-13-01",
+		"missing_number": "now-d",
+		"missing_unit":   "now+1",
+		"unknown_unit":   "now/q",
+		"missing_anchor": "||+1d",
+	}
+
+	for name, input := range tcs {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseDate(input); err == nil {
+				t.Fatalf("expected an error parsing [%s]", input)
+			}
+		})
+	}
+}
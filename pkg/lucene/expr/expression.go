@@ -37,15 +37,18 @@ type Expression struct {
 	Right any      `json:"right,omitempty"`
 
 	// these are operator specific states we have to track
-	boostPower    float64
-	fuzzyDistance int
+	boostPower        float64
+	fuzzyDistance     int
+	proximityDistance int
 }
 
-// RangeBoundary represents the boundary conditions for a range operator
+// RangeBoundary represents the boundary conditions for a range operator. Each side of the range
+// can be inclusive or exclusive independently, e.g. [18 TO 65} includes 18 but not 65.
 type RangeBoundary struct {
-	Min       any  `json:"min"`
-	Max       any  `json:"max"`
-	Inclusive bool `json:"inclusive"`
+	Min          any
+	Max          any
+	MinInclusive bool
+	MaxInclusive bool
 }
 
 func (e Expression) String() string {
@@ -72,6 +75,22 @@ func (e Expression) GoString() string {
 	return renderer(&e, true)
 }
 
+// BoostPower returns the power of a BOOST expression. It defaults to 1.0 if no power was given.
+func (e Expression) BoostPower() float64 {
+	return e.boostPower
+}
+
+// FuzzyDistance returns the edit distance of a FUZZY expression. It defaults to 1 if no distance was given.
+func (e Expression) FuzzyDistance() int {
+	return e.fuzzyDistance
+}
+
+// ProximityDistance returns the slop of a PROXIMITY expression, the number of positions the terms in the
+// phrase are allowed to move. It defaults to 1 if no distance was given.
+func (e Expression) ProximityDistance() int {
+	return e.proximityDistance
+}
+
 // Lit represents a literal expression
 func Lit(in any) *Expression {
 	return Expr(in, Literal)
@@ -136,6 +155,11 @@ func Rang(term any, min, max any, inclusive bool) *Expression {
 	return Expr(term, Range, min, max, inclusive)
 }
 
+// RangBounds creates a new range expression where the inclusivity of each side is set separately
+func RangBounds(term any, min, max any, minInclusive, maxInclusive bool) *Expression {
+	return Expr(term, Range, min, max, minInclusive, maxInclusive)
+}
+
 // NOT wraps an expression in a Not
 func NOT(e any) *Expression {
 	return Expr(e, Not)
@@ -167,14 +191,36 @@ func FUZZY(e any, distance ...int) *Expression {
 	return Expr(e, Fuzzy)
 }
 
+// PROXIMITY wraps a phrase in a proximity search
+func PROXIMITY(e any, distance ...int) *Expression {
+	if len(distance) > 0 {
+		return Expr(e, Proximity, distance[0])
+	}
+	return Expr(e, Proximity)
+}
+
+// EXISTS creates an expression that matches when the field has a value
+func EXISTS(field any) *Expression {
+	return Expr(field, Exists)
+}
+
 // IsExpr checks if the input is an expression
 func IsExpr(in any) bool {
 	_, isExpr := in.(*Expression)
 	return isExpr
 }
 
-// Validate validates the expression is correctly structured.
-func Validate(in any) (err error) {
+// Validate validates the expression is correctly structured. If a schema is passed with WithSchema the
+// values compared against each field are also checked against the field's type and coerced into it.
+func Validate(in any, opts ...ValidateOption) (err error) {
+	o := validateOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return validate(in, o)
+}
+
+func validate(in any, o validateOptions) (err error) {
 	e, isExpr := in.(*Expression)
 	if !isExpr {
 		// if we don't have an expression we must be in a leaf node
@@ -190,12 +236,17 @@ func Validate(in any) (err error) {
 		return err
 	}
 
-	err = Validate(e.Left)
+	err = checkSchema(e, o.schema)
 	if err != nil {
 		return err
 	}
 
-	return Validate(e.Right)
+	err = validate(e.Left, o)
+	if err != nil {
+		return err
+	}
+
+	return validate(e.Right, o)
 }
 
 // Column represents a column in sql. It will not be escaped by quotes in the sql rendering
@@ -246,12 +297,33 @@ func Expr(left any, op Operator, right ...any) *Expression {
 		return e
 	}
 
+	// support changing proximity distance
+	if op == Proximity {
+		e.proximityDistance = 1
+		if len(right) == 1 && isInt(right[0]) {
+			e.proximityDistance = right[0].(int)
+		}
+		return e
+	}
+
 	// support passing a range with inclusivity
 	if op == Range && len(right) == 3 && isBool(right[2]) {
 		e.Right = &RangeBoundary{
-			Min:       literalToExpr(right[0]),
-			Max:       literalToExpr(right[1]),
-			Inclusive: right[2].(bool),
+			Min:          literalToExpr(right[0]),
+			Max:          literalToExpr(right[1]),
+			MinInclusive: right[2].(bool),
+			MaxInclusive: right[2].(bool),
+		}
+		return e
+	}
+
+	// support passing a range with separate inclusivity for each side
+	if op == Range && len(right) == 4 && isBool(right[2]) && isBool(right[3]) {
+		e.Right = &RangeBoundary{
+			Min:          literalToExpr(right[0]),
+			Max:          literalToExpr(right[1]),
+			MinInclusive: right[2].(bool),
+			MaxInclusive: right[3].(bool),
 		}
 		return e
 	}
@@ -301,6 +373,54 @@ type jsonExpression struct {
 	BoostPower    *float64       `json:"power,omitempty"`
 }
 
+type jsonRangeBoundary struct {
+	Min          any   `json:"min"`
+	Max          any   `json:"max"`
+	Inclusive    *bool `json:"inclusive,omitempty"`
+	MinInclusive *bool `json:"min_inclusive,omitempty"`
+	MaxInclusive *bool `json:"max_inclusive,omitempty"`
+}
+
+// MarshalJSON is a custom JSON serialization for the RangeBoundary. Ranges with the same inclusivity
+// on both sides use a single inclusive key.
+func (b RangeBoundary) MarshalJSON() (out []byte, err error) {
+	c := jsonRangeBoundary{
+		Min: b.Min,
+		Max: b.Max,
+	}
+	if b.MinInclusive == b.MaxInclusive {
+		c.Inclusive = &b.MinInclusive
+	} else {
+		c.MinInclusive = &b.MinInclusive
+		c.MaxInclusive = &b.MaxInclusive
+	}
+	return json.Marshal(c)
+}
+
+// UnmarshalJSON is a custom JSON deserialization for the RangeBoundary. The min_inclusive and
+// max_inclusive keys take precedence over the inclusive key.
+func (b *RangeBoundary) UnmarshalJSON(data []byte) (err error) {
+	var c jsonRangeBoundary
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return err
+	}
+
+	b.Min = c.Min
+	b.Max = c.Max
+	if c.Inclusive != nil {
+		b.MinInclusive = *c.Inclusive
+		b.MaxInclusive = *c.Inclusive
+	}
+	if c.MinInclusive != nil {
+		b.MinInclusive = *c.MinInclusive
+	}
+	if c.MaxInclusive != nil {
+		b.MaxInclusive = *c.MaxInclusive
+	}
+	return nil
+}
+
 // MarshalJSON is a custom JSON serialization for the Expression
 func (e Expression) MarshalJSON() (out []byte, err error) {
 	// if we are in a leaf node just marshal the value
@@ -336,6 +456,11 @@ func (e Expression) MarshalJSON() (out []byte, err error) {
 		c.FuzzyDistance = &e.fuzzyDistance
 	}
 
+	// proximity shares the distance key with fuzzy since an expression can't be both
+	if e.Op == Proximity && e.proximityDistance != 1 {
+		c.FuzzyDistance = &e.proximityDistance
+	}
+
 	return json.Marshal(c)
 }
 
@@ -420,6 +545,13 @@ func (e *Expression) UnmarshalJSON(data []byte) (err error) {
 		}
 	}
 
+	if e.Op == Proximity {
+		e.proximityDistance = 1
+		if c.FuzzyDistance != nil {
+			e.proximityDistance = *c.FuzzyDistance
+		}
+	}
+
 	if e.Op == Boost {
 		e.boostPower = 1.0
 		if c.BoostPower != nil {
@@ -499,6 +631,11 @@ func literalToExpr(in any) *Expression {
 		return WILD(s)
 	}
 
+	// if it parses as a date or date math then it is a date. A bare now is only a date when compared against
+	if d, err := ParseDate(s); err == nil && s != "now" {
+		return Lit(d)
+	}
+
 	return Lit(s)
 }
 
@@ -533,7 +670,8 @@ func operatesOnColumn(op Operator) bool {
 		op == GreaterEq ||
 		op == LessEq ||
 		op == In ||
-		op == Like
+		op == Like ||
+		op == Exists
 }
 
 // wrapInColumn converts a string to a column and enforces column
@@ -571,8 +709,9 @@ func toIntIfNecessary(in any) (out any) {
 
 func empty() Expression {
 	return Expression{
-		fuzzyDistance: 1,
-		boostPower:    1.0,
+		fuzzyDistance:     1,
+		proximityDistance: 1,
+		boostPower:        1.0,
 	}
 }
 
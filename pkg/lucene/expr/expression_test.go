@@ -63,6 +63,26 @@ func TestExprJSON(t *testing.T) {
 			  }`,
 			want: Rang("a", 1, 2, false),
 		},
+		"flat_exists": {
+			input: `{
+				"left": "a",
+				"operator": "EXISTS"
+			}`,
+			want: EXISTS("a"),
+		},
+		"flat_half_open_range": {
+			input: `{
+				"left": "a",
+				"operator": "RANGE",
+				"right": {
+					"min": 1,
+					"max": 2,
+					"min_inclusive": true,
+					"max_inclusive": false
+				}
+			  }`,
+			want: RangBounds("a", 1, 2, true, false),
+		},
 		"flat_range_with_float": {
 			input: `{
 				"left": "a",
@@ -141,6 +161,25 @@ func TestExprJSON(t *testing.T) {
 			}`,
 			want: FUZZY("a", 2),
 		},
+		"flat_proximity": {
+			input: `{
+				"left": "foo bar",
+				"operator": "PROXIMITY",
+				"distance": 3
+			}`,
+			want: PROXIMITY(Lit("foo bar"), 3),
+		},
+		"proximity_on_field": {
+			input: `{
+				"left": {
+					"left": "a",
+					"operator": "EQUALS",
+					"right": "foo bar"
+				},
+				"operator": "PROXIMITY"
+			}`,
+			want: PROXIMITY(Eq("a", "foo bar")),
+		},
 		"flat_in_list": {
 			input: `{
 				"left": "a",
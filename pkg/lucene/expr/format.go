@@ -0,0 +1,287 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Format renders the expression as a canonical lucene query. Unlike String, the output is always
+// valid lucene syntax and parsing it gives back an expression with the same structure. Parentheses
+// are only added where they are needed to keep that structure.
+//
+// A few values can't be written in lucene syntax and are formatted as close as possible. These are
+// strings that contain both a double quote and a backslash or wildcard, and values that only come
+// from building expressions by hand like bools and resolved times.
+func Format(e *Expression) string {
+	if e == nil {
+		return ""
+	}
+	return format(e, termPlain)
+}
+
+// termContext is how a term must be written to be parsed back into the same expression
+type termContext int
+
+const (
+	// termPlain terms can be quoted or bare
+	termPlain termContext = iota
+	// termFuzzy terms must be bare since a ~ after a quoted term is a proximity search
+	termFuzzy
+	// termPhrase terms must be quoted
+	termPhrase
+)
+
+func format(e *Expression, ctx termContext) string {
+	switch e.Op {
+	case And, Or:
+		return fmt.Sprintf(
+			"%s %s %s",
+			formatOperand(e.Left, e.Op, false),
+			toString[e.Op],
+			formatOperand(e.Right, e.Op, true),
+		)
+	case Not:
+		return fmt.Sprintf("NOT %s", formatPrefixed(e.Left))
+	case Must:
+		return fmt.Sprintf("+%s", formatPrefixed(e.Left))
+	case MustNot:
+		return formatMustNot(formatPrefixed(e.Left))
+	case Boost:
+		return fmt.Sprintf("%s^%s", formatSuffixed(e.Left, ctx), strconv.FormatFloat(e.boostPower, 'f', -1, 64))
+	case Fuzzy:
+		return fmt.Sprintf("%s~%d", formatSuffixed(e.Left, termFuzzy), e.fuzzyDistance)
+	case Proximity:
+		return fmt.Sprintf("%s~%d", formatSuffixed(e.Left, termPhrase), e.proximityDistance)
+	case Equals, Like:
+		return fmt.Sprintf("%s:%s", formatField(e.Left), formatValue(e.Right, ctx))
+	case Greater:
+		return fmt.Sprintf("%s:>%s", formatField(e.Left), formatValue(e.Right, termPlain))
+	case GreaterEq:
+		return fmt.Sprintf("%s:>=%s", formatField(e.Left), formatValue(e.Right, termPlain))
+	case Less:
+		return fmt.Sprintf("%s:<%s", formatField(e.Left), formatValue(e.Right, termPlain))
+	case LessEq:
+		return fmt.Sprintf("%s:<=%s", formatField(e.Left), formatValue(e.Right, termPlain))
+	case Range:
+		return formatRange(e)
+	case In:
+		return fmt.Sprintf("%s:%s", formatField(e.Left), formatValue(e.Right, termPlain))
+	case List:
+		vals, _ := e.Left.([]*Expression)
+		strs := make([]string, 0, len(vals))
+		for _, v := range vals {
+			strs = append(strs, format(v, termPlain))
+		}
+		return fmt.Sprintf("(%s)", strings.Join(strs, " OR "))
+	case Exists:
+		return fmt.Sprintf("_exists_:%s", formatField(e.Left))
+	case Wild, Regexp:
+		return fmt.Sprintf("%v", e.Left)
+	case Literal:
+		return formatTerm(e.Left, ctx)
+	}
+	return e.String()
+}
+
+// formatOperand formats a side of an AND or OR. The parser is left associative and AND binds tighter
+// than OR so a sub expression needs parentheses when it binds looser or when it is on the right.
+func formatOperand(in any, op Operator, isRight bool) string {
+	e, isExpr := in.(*Expression)
+	if !isExpr {
+		return formatTerm(in, termPlain)
+	}
+
+	s := format(e, termPlain)
+	if e.Op != And && e.Op != Or {
+		return s
+	}
+	if (op == And && e.Op == Or) || (isRight && e.Op == op) {
+		return fmt.Sprintf("(%s)", s)
+	}
+	return s
+}
+
+// formatPrefixed formats the sub expression of a prefix operator like NOT or -
+func formatPrefixed(in any) string {
+	e, isExpr := in.(*Expression)
+	if !isExpr {
+		return formatTerm(in, termPlain)
+	}
+
+	if isCompound(e) {
+		return fmt.Sprintf("(%s)", format(e, termPlain))
+	}
+	return format(e, termPlain)
+}
+
+// formatMustNot prefixes the formatted operand with -. An operand that starts with a digit or a - is
+// wrapped in parentheses so the - isn't read back as part of a negative number.
+func formatMustNot(operand string) string {
+	if operand != "" && (operand[0] == '-' || unicode.IsDigit(rune(operand[0]))) {
+		return fmt.Sprintf("-(%s)", operand)
+	}
+	return fmt.Sprintf("-%s", operand)
+}
+
+// formatSuffixed formats the sub expression of a suffix operator like ^ or ~
+func formatSuffixed(in any, ctx termContext) string {
+	e, isExpr := in.(*Expression)
+	if !isExpr {
+		return formatTerm(in, ctx)
+	}
+
+	if isCompound(e) {
+		return fmt.Sprintf("(%s)", format(e, termPlain))
+	}
+	return format(e, ctx)
+}
+
+// isCompound checks if the expression is built out of other expressions rather than a single term
+func isCompound(e *Expression) bool {
+	switch e.Op {
+	case And, Or, Not, Must, MustNot, Boost, Fuzzy, Proximity:
+		return true
+	}
+	return false
+}
+
+func formatRange(e *Expression) string {
+	boundary, ok := e.Right.(*RangeBoundary)
+	if !ok {
+		return e.String()
+	}
+
+	open, closed := "{", "}"
+	if boundary.MinInclusive {
+		open = "["
+	}
+	if boundary.MaxInclusive {
+		closed = "]"
+	}
+	return fmt.Sprintf(
+		"%s:%s%s TO %s%s",
+		formatField(e.Left),
+		open,
+		formatValue(boundary.Min, termPlain),
+		formatValue(boundary.Max, termPlain),
+		closed,
+	)
+}
+
+func formatValue(in any, ctx termContext) string {
+	e, isExpr := in.(*Expression)
+	if !isExpr {
+		return formatTerm(in, ctx)
+	}
+	return format(e, ctx)
+}
+
+// formatField formats the field on the left side of an expression
+func formatField(in any) string {
+	e, isExpr := in.(*Expression)
+	if isExpr && e.Op == Literal {
+		in = e.Left
+	}
+
+	name := fmt.Sprintf("%v", in)
+	switch {
+	case isBareWord(name):
+		return name
+	case strings.ContainsAny(name, "*?"):
+		// like wildcard terms, fields with a wildcard keep the escapes from the query so they are
+		// written as they are
+		return name
+	}
+	return escape(name)
+}
+
+// formatTerm formats a single value
+func formatTerm(in any, ctx termContext) string {
+	switch v := in.(type) {
+	case Column:
+		return formatField(string(v))
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			// make sure it isn't parsed back as an int
+			s += ".0"
+		}
+		return s
+	case Date:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case string:
+		return formatString(v, ctx)
+	}
+	return fmt.Sprintf("%v", in)
+}
+
+// formatString writes the string bare when it can, otherwise quoted or escaped depending on where it is
+func formatString(s string, ctx termContext) string {
+	quotable := !strings.Contains(s, `"`)
+	escapable := !strings.ContainsAny(s, `\*?`) && s != ""
+
+	switch {
+	case ctx != termPhrase && isBareWord(s) && !looksTyped(s):
+		return s
+	case ctx != termFuzzy && quotable:
+		return fmt.Sprintf(`"%s"`, s)
+	case ctx != termPhrase && escapable:
+		return escape(s)
+	}
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(s, `"`, ""))
+}
+
+// isBareWord checks if the string can be written without quotes or escapes
+func isBareWord(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "NOT", "TO":
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		case (r == '.' || r == '-') && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// looksTyped checks if a bare string would be parsed as something other than a string
+func looksTyped(s string) bool {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	return s != "now" && IsDate(s)
+}
+
+// escape backslash escapes everything in the string that isn't a letter or digit
+func escape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+
+	escaped := sb.String()
+	if !strings.Contains(escaped, `\`) && looksTyped(s) {
+		// escape the first character so numbers and dates stay strings
+		escaped = `\` + escaped
+	}
+	return escaped
+}
@@ -0,0 +1,94 @@
+package expr
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	type tc struct {
+		input *Expression
+		want  string
+	}
+
+	tcs := map[string]tc{
+		"equals": {
+			input: Eq("a", "b"),
+			want:  "a:b",
+		},
+		"comparisons": {
+			input: AND(GREATER("a", 5), LESSEQ("b", 2.5)),
+			want:  "a:>5 AND b:<=2.5",
+		},
+		"range": {
+			input: RangBounds("a", 1, "*", true, false),
+			want:  "a:[1 TO *}",
+		},
+		"in": {
+			input: IN("a", LIST(Lit("b"), Lit("c d"))),
+			want:  `a:(b OR "c d")`,
+		},
+		"exists": {
+			input: EXISTS("a"),
+			want:  "_exists_:a",
+		},
+		"wildcard_and_regexp": {
+			input: OR(Eq("a", WILD("b*")), Eq("c", REGEXP("/d [e]/"))),
+			want:  "a:b* OR c:/d [e]/",
+		},
+		"or_inside_and": {
+			input: AND(OR(Lit("a"), Lit("b")), Lit("c")),
+			want:  "(a OR b) AND c",
+		},
+		"and_inside_or": {
+			input: OR(Lit("a"), AND(Lit("b"), Lit("c"))),
+			want:  "a OR b AND c",
+		},
+		"right_nested": {
+			input: AND(Lit("a"), AND(Lit("b"), Lit("c"))),
+			want:  "a AND (b AND c)",
+		},
+		"prefix_operators": {
+			input: AND(MUST(Eq("a", "b")), AND(MUSTNOT(Lit("c")), NOT(NOT(Lit("d"))))),
+			want:  "+a:b AND (-c AND NOT (NOT d))",
+		},
+		"must_not_numbers": {
+			input: AND(MUSTNOT(Lit(5)), MUSTNOT(Lit(-5))),
+			want:  "-(5) AND -(-5)",
+		},
+		"escaped_wildcard_in_field": {
+			input: Eq(`a\*b`, "c"),
+			want:  `a\*b:c`,
+		},
+		"boost": {
+			input: BOOST(OR(Lit("a"), Lit("b")), 2.5),
+			want:  "(a OR b)^2.5",
+		},
+		"fuzzy_with_space": {
+			input: FUZZY(Eq("a", "b c"), 2),
+			want:  `a:b\ c~2`,
+		},
+		"proximity": {
+			input: PROXIMITY(Lit("a b"), 3),
+			want:  `"a b"~3`,
+		},
+		"strings_that_look_typed": {
+			input: AND(Eq("a", Lit("5")), Eq("b", Lit("2024-01-01"))),
+			want:  `a:"5" AND b:"2024-01-01"`,
+		},
+		"typed_values": {
+			input: AND(Eq("a", 2.0), Eq("b", "2024-01-01")),
+			want:  "a:2.0 AND b:2024-01-01",
+		},
+		"keywords_and_symbols": {
+			input: AND(Lit("AND"), Eq("foo bar", `say "hi"`)),
+			want:  `"AND" AND foo\ bar:say\ \"hi\"`,
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := Format(tc.input)
+			if got != tc.want {
+				t.Fatalf(errTemplate, "formatted query doesn't match", tc.want, got)
+			}
+		})
+	}
+}
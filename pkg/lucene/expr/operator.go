@@ -31,6 +31,8 @@ const (
 	LessEq
 	In
 	List
+	Proximity
+	Exists
 )
 
 // String renders the operator as a string
@@ -58,6 +60,8 @@ var fromString = map[string]Operator{
 	"LESS_EQ":    LessEq,
 	"IN":         In,
 	"LIST":       List,
+	"PROXIMITY":  Proximity,
+	"EXISTS":     Exists,
 }
 
 var toString = map[Operator]string{
@@ -80,4 +84,6 @@ var toString = map[Operator]string{
 	LessEq:    "LESS_EQ",
 	In:        "IN",
 	List:      "LIST",
+	Proximity: "PROXIMITY",
+	Exists:    "EXISTS",
 }
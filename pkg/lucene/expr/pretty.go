@@ -0,0 +1,104 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultWidth is the line width Pretty uses when it is given a width of 0 or less
+const DefaultWidth = 80
+
+const prettyIndent = "  "
+
+// Pretty formats the expression like Format but breaks it over multiple lines when it doesn't fit in
+// width. Each clause of a long AND or OR chain goes on its own line starting with its operator and
+// nested groups are indented inside their parentheses. Anything that fits in the width stays on a
+// single line. Like Format the output parses back into the same expression.
+func Pretty(e *Expression, width int) string {
+	if e == nil {
+		return ""
+	}
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	p := printer{width: width}
+	return p.print(e, "")
+}
+
+type printer struct {
+	width int
+}
+
+// print formats the expression at the given indentation, assuming the line already has the indent on it
+func (p printer) print(e *Expression, indent string) string {
+	flat := format(e, termPlain)
+	if len(indent)+len(flat) <= p.width {
+		return flat
+	}
+
+	switch e.Op {
+	case And, Or:
+		operands := chain(e, e.Op)
+		lines := make([]string, 0, len(operands))
+		for i, operand := range operands {
+			s := p.operand(operand, indent)
+			if i > 0 {
+				s = fmt.Sprintf("%s %s", toString[e.Op], s)
+			}
+			lines = append(lines, s)
+		}
+		return strings.Join(lines, "\n"+indent)
+	case Not:
+		return fmt.Sprintf("NOT %s", p.group(e.Left, indent, isCompound))
+	case Must:
+		return fmt.Sprintf("+%s", p.group(e.Left, indent, isCompound))
+	case MustNot:
+		return formatMustNot(p.group(e.Left, indent, isCompound))
+	case Boost:
+		return fmt.Sprintf("%s^%s", p.group(e.Left, indent, isCompound), strconv.FormatFloat(e.boostPower, 'f', -1, 64))
+	}
+
+	// everything else is a single term that can't be broken up
+	return flat
+}
+
+// operand prints a clause of an AND or OR chain. Compound clauses are always wrapped in parentheses
+// when they are broken up so the nesting is obvious, even where the parser wouldn't need them.
+func (p printer) operand(e *Expression, indent string) string {
+	return p.group(e, indent, func(e *Expression) bool {
+		return e.Op == And || e.Op == Or
+	})
+}
+
+// group prints a sub expression, wrapping it in parentheses when needsParens says so. If the group
+// doesn't fit on the line its contents are indented on their own lines.
+func (p printer) group(in any, indent string, needsParens func(*Expression) bool) string {
+	e, isExpr := in.(*Expression)
+	if !isExpr {
+		return formatTerm(in, termPlain)
+	}
+	if !needsParens(e) {
+		return p.print(e, indent)
+	}
+
+	flat := fmt.Sprintf("(%s)", format(e, termPlain))
+	if len(indent)+len(flat) <= p.width {
+		return flat
+	}
+
+	inner := indent + prettyIndent
+	return fmt.Sprintf("(\n%s%s\n%s)", inner, p.print(e, inner), indent)
+}
+
+// chain returns the clauses of a left associative chain of the same operator. A clause on the
+// right that uses the same operator is kept whole since it was grouped in the original query.
+func chain(e *Expression, op Operator) []*Expression {
+	left, _ := e.Left.(*Expression)
+	right, _ := e.Right.(*Expression)
+
+	if left != nil && left.Op == op {
+		return append(chain(left, op), right)
+	}
+	return []*Expression{left, right}
+}
@@ -0,0 +1,79 @@
+package expr
+
+import "testing"
+
+func TestPretty(t *testing.T) {
+	type tc struct {
+		input *Expression
+		width int
+		want  string
+	}
+
+	long := AND(
+		AND(
+			OR(Eq("title", "pink panther"), Eq("title", "return of the pink panther")),
+			Rang("year", 1960, 1980, true),
+		),
+		NOT(OR(Eq("genre", "horror"), Eq("genre", "thriller"))),
+	)
+
+	tcs := map[string]tc{
+		"fits_on_one_line": {
+			input: AND(Eq("a", "b"), OR(Lit("c"), Lit("d"))),
+			width: 80,
+			want:  "a:b AND (c OR d)",
+		},
+		"one_clause_per_line": {
+			input: long,
+			width: 60,
+			want: `(title:"pink panther" OR title:"return of the pink panther")
+AND year:[1960 TO 1980]
+AND NOT (genre:horror OR genre:thriller)`,
+		},
+		"nested_groups_are_indented": {
+			input: long,
+			width: 30,
+			want: `(
+  title:"pink panther"
+  OR title:"return of the pink panther"
+)
+AND year:[1960 TO 1980]
+AND NOT (
+  genre:horror
+  OR genre:thriller
+)`,
+		},
+		"and_inside_or_gets_parens_when_broken": {
+			input: OR(Lit("aaaa"), AND(Lit("bbbb"), Lit("cccc"))),
+			width: 10,
+			want: `aaaa
+OR (
+  bbbb
+  AND cccc
+)`,
+		},
+		"right_nested_chain_is_kept": {
+			input: AND(Lit("aaaa"), AND(Lit("bbbb"), Lit("cccc"))),
+			width: 10,
+			want: `aaaa
+AND (
+  bbbb
+  AND cccc
+)`,
+		},
+		"default_width": {
+			input: AND(Eq("a", "b"), Eq("c", "d")),
+			width: 0,
+			want:  "a:b AND c:d",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := Pretty(tc.input, tc.width)
+			if got != tc.want {
+				t.Fatalf(jsonErrTemplate, "pretty printed query doesn't match", tc.want, got)
+			}
+		})
+	}
+}
@@ -27,6 +27,8 @@ var renderers = map[Operator]renderer{
 	Like:      renderBasic,
 	In:        renderBasic,
 	List:      renderList,
+	Proximity: renderProximity,
+	Exists:    renderExists,
 }
 
 func renderEquals(e *Expression, verbose bool) string {
@@ -96,20 +98,45 @@ func renderFuzzy(e *Expression, verbose bool) string {
 	return fmt.Sprintf("%s~", e.Left)
 }
 
-func renderRange(e *Expression, verbose bool) string {
-	boundary := e.Right.(*RangeBoundary)
+func renderProximity(e *Expression, verbose bool) string {
 	if verbose {
-		if boundary.Inclusive {
-			return fmt.Sprintf("%#v:[%#v TO %#v]", e.Left, boundary.Min, boundary.Max)
+		if e.proximityDistance > 1 {
+			return fmt.Sprintf("%s(%#v~%d)", toString[e.Op], e.Left, e.proximityDistance)
 		}
 
-		return fmt.Sprintf("%#v:{%#v TO %#v}", e.Left, boundary.Min, boundary.Max)
+		return fmt.Sprintf("%s(%#v)", toString[e.Op], e.Left)
+	}
+
+	if e.proximityDistance > 1 {
+		return fmt.Sprintf("%s~%d", e.Left, e.proximityDistance)
 	}
-	if boundary.Inclusive {
-		return fmt.Sprintf("%s:[%s TO %s]", e.Left, boundary.Min, boundary.Max)
+
+	return fmt.Sprintf("%s~", e.Left)
+}
+
+func renderExists(e *Expression, verbose bool) string {
+	if verbose {
+		return fmt.Sprintf("%s(%#v)", toString[e.Op], e.Left)
+	}
+
+	return fmt.Sprintf("_exists_:%s", e.Left)
+}
+
+func renderRange(e *Expression, verbose bool) string {
+	boundary := e.Right.(*RangeBoundary)
+	open, closed := "{", "}"
+	if boundary.MinInclusive {
+		open = "["
+	}
+	if boundary.MaxInclusive {
+		closed = "]"
+	}
+
+	if verbose {
+		return fmt.Sprintf("%#v:%s%#v TO %#v%s", e.Left, open, boundary.Min, boundary.Max, closed)
 	}
 
-	return fmt.Sprintf("%s:{%s TO %s}", e.Left, boundary.Min, boundary.Max)
+	return fmt.Sprintf("%s:%s%s TO %s%s", e.Left, open, boundary.Min, boundary.Max, closed)
 }
 
 func renderList(e *Expression, verbose bool) string {
@@ -0,0 +1,93 @@
+package expr
+
+import "fmt"
+
+// RewriteFunc returns the replacement for the expression in the cursor. Returning c.Expr keeps the
+// expression as is and returning nil drops it from the tree.
+type RewriteFunc func(c Cursor) *Expression
+
+// Rewrite rebuilds the expression bottom up by calling fn on a copy of every sub expression after its
+// children have been rewritten, so fn always sees the rewritten children. The cursor's parent is the
+// parent in the original tree and a depth of 0 is the root, which makes it easy to wrap the whole query.
+// The input expression is never modified.
+//
+// Copies keep everything from the original expression, including the boost power and fuzzy
+// distance, so fn only has to change what it cares about. When a sub expression is dropped an AND or
+// OR collapses into its other side and any other expression that depends on it is dropped as well.
+// If everything is dropped Rewrite returns nil.
+//
+// The result is checked with Validate before it is returned.
+func Rewrite(e *Expression, fn RewriteFunc) (out *Expression, err error) {
+	out = rewrite(Cursor{Expr: e}, fn)
+	if out == nil {
+		return nil, nil
+	}
+
+	err = Validate(out)
+	if err != nil {
+		return nil, fmt.Errorf("rewritten expression is invalid: %w", err)
+	}
+	return out, nil
+}
+
+func rewrite(c Cursor, fn RewriteFunc) *Expression {
+	if c.Expr == nil {
+		return nil
+	}
+
+	cp := *c.Expr
+	child := Cursor{Parent: c.Expr, Depth: c.Depth + 1}
+
+	left, leftDropped := rewriteSide(c.Expr.Left, child, fn)
+	right, rightDropped := rewriteSide(c.Expr.Right, child, fn)
+	cp.Left, cp.Right = left, right
+
+	switch {
+	case (cp.Op == And || cp.Op == Or) && leftDropped && rightDropped:
+		return nil
+	case (cp.Op == And || cp.Op == Or) && leftDropped:
+		return right.(*Expression)
+	case (cp.Op == And || cp.Op == Or) && rightDropped:
+		return left.(*Expression)
+	case leftDropped || rightDropped:
+		return nil
+	}
+
+	return fn(Cursor{Expr: &cp, Parent: c.Parent, Depth: c.Depth})
+}
+
+// rewriteSide rewrites one side of an expression. It reports whether the side was dropped entirely.
+func rewriteSide(side any, c Cursor, fn RewriteFunc) (out any, dropped bool) {
+	switch v := side.(type) {
+	case *Expression:
+		if v == nil {
+			return v, false
+		}
+		c.Expr = v
+		sub := rewrite(c, fn)
+		return sub, sub == nil
+	case []*Expression:
+		subs := make([]*Expression, 0, len(v))
+		for _, e := range v {
+			c.Expr = e
+			if sub := rewrite(c, fn); sub != nil {
+				subs = append(subs, sub)
+			}
+		}
+		return subs, len(subs) == 0 && len(v) > 0
+	case *RangeBoundary:
+		if v == nil {
+			return v, false
+		}
+		b := *v
+		for _, bound := range []*any{&b.Min, &b.Max} {
+			sub, dropped := rewriteSide(*bound, c, fn)
+			if dropped {
+				return nil, true
+			}
+			*bound = sub
+		}
+		return &b, false
+	}
+	return side, false
+}
@@ -0,0 +1,127 @@
+package expr
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	type tc struct {
+		input *Expression
+		fn    RewriteFunc
+		want  *Expression
+		err   string
+	}
+
+	renameField := func(from, to string) RewriteFunc {
+		return func(c Cursor) *Expression {
+			if c.Expr.Op == Literal && c.Expr.Left == Column(from) {
+				return Lit(Column(to))
+			}
+			return c.Expr
+		}
+	}
+
+	dropField := func(field string) RewriteFunc {
+		return func(c Cursor) *Expression {
+			if operatesOnColumn(c.Expr.Op) && reflect.DeepEqual(c.Expr.Left, Lit(Column(field))) {
+				return nil
+			}
+			return c.Expr
+		}
+	}
+
+	tcs := map[string]tc{
+		"identity": {
+			input: AND(Eq("a", "b"), NOT(Rang("c", 1, "*", true))),
+			fn:    func(c Cursor) *Expression { return c.Expr },
+			want:  AND(Eq("a", "b"), NOT(Rang("c", 1, "*", true))),
+		},
+		"rename_field": {
+			input: OR(Eq("a", "b"), IN("a", LIST(Lit("c"), Lit("d")))),
+			fn:    renameField("a", "title"),
+			want:  OR(Eq("title", "b"), IN("title", LIST(Lit("c"), Lit("d")))),
+		},
+		"inject_clause_at_root": {
+			input: OR(Eq("a", "b"), Eq("c", "d")),
+			fn: func(c Cursor) *Expression {
+				if c.Depth == 0 {
+					return AND(Eq("tenant_id", "x"), c.Expr)
+				}
+				return c.Expr
+			},
+			want: AND(Eq("tenant_id", "x"), OR(Eq("a", "b"), Eq("c", "d"))),
+		},
+		"replace_synonyms": {
+			input: AND(Eq("type", "car"), Lit("car")),
+			fn: func(c Cursor) *Expression {
+				if c.Expr.Op == Literal && c.Expr.Left == "car" && c.Parent != nil && c.Parent.Op == Equals {
+					return Lit("automobile")
+				}
+				return c.Expr
+			},
+			want: AND(Eq("type", "automobile"), Lit("car")),
+		},
+		"keeps_boost_and_fuzzy": {
+			input: AND(BOOST(Eq("a", "b"), 2.5), FUZZY(Eq("a", "c"), 2)),
+			fn:    renameField("a", "title"),
+			want:  AND(BOOST(Eq("title", "b"), 2.5), FUZZY(Eq("title", "c"), 2)),
+		},
+		"drop_collapses_and": {
+			input: AND(Eq("secret", "b"), OR(Eq("c", "d"), NOT(Eq("secret", "e")))),
+			fn:    dropField("secret"),
+			want:  Eq("c", "d"),
+		},
+		"drop_everything": {
+			input: MUSTNOT(Eq("secret", "b")),
+			fn:    dropField("secret"),
+			want:  nil,
+		},
+		"drop_list_value": {
+			input: IN("a", LIST(Lit("b"), Lit("c"))),
+			fn: func(c Cursor) *Expression {
+				if c.Expr.Op == Literal && c.Expr.Left == "b" {
+					return nil
+				}
+				return c.Expr
+			},
+			want: IN("a", LIST(Lit("c"))),
+		},
+		"invalid_result": {
+			input: Eq("a", "b"),
+			fn: func(c Cursor) *Expression {
+				if c.Expr.Op == Equals {
+					c.Expr.Left = AND(Lit("x"), Lit("y"))
+				}
+				return c.Expr
+			},
+			err: "rewritten expression is invalid",
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			original := tc.input.String()
+
+			got, err := Rewrite(tc.input, tc.fn)
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("expected error containing [%s], got: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wanted no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf(errTemplate, "rewritten expression doesn't match", tc.want, got)
+			}
+
+			if tc.input.String() != original {
+				t.Fatalf(errTemplate, "input expression was modified", original, tc.input.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema maps field names to the type of value they hold. It is used by Validate to reject values
+// that can't be stored in a field (e.g. age:abc) and to coerce literals into the field's type before
+// the expression is rendered. Fields that are not in the schema are not checked.
+type Schema map[string]FieldType
+
+// FieldType is the type of value a field holds
+type FieldType struct {
+	kind   fieldKind
+	values []string // the allowed values of an enum
+}
+
+type fieldKind int
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindFloat
+	kindBool
+	kindDate
+	kindEnum
+)
+
+// the types that can be used in a schema
+var (
+	TypeString = FieldType{kind: kindString}
+	TypeInt    = FieldType{kind: kindInt}
+	TypeFloat  = FieldType{kind: kindFloat}
+	TypeBool   = FieldType{kind: kindBool}
+	TypeDate   = FieldType{kind: kindDate}
+)
+
+// Enum creates a string field type that only accepts the given values
+func Enum(values ...string) FieldType {
+	return FieldType{kind: kindEnum, values: values}
+}
+
+// String renders the field type
+func (t FieldType) String() string {
+	switch t.kind {
+	case kindInt:
+		return "int"
+	case kindFloat:
+		return "float"
+	case kindBool:
+		return "bool"
+	case kindDate:
+		return "date"
+	case kindEnum:
+		return fmt.Sprintf("enum(%s)", strings.Join(t.values, ", "))
+	default:
+		return "string"
+	}
+}
+
+// TypeError is returned by Validate when a value in the expression doesn't match the type of its field
+// in the schema or the field's type doesn't support the operator.
+type TypeError struct {
+	Field string    // the field the value is compared against
+	Type  FieldType // the type of the field in the schema
+	Op    Operator  // the operator the field is used with
+	Value any       // the value that couldn't be coerced or nil if the operator isn't supported
+}
+
+// Error renders the type error
+func (e *TypeError) Error() string {
+	if e.Value == nil {
+		return fmt.Sprintf("field [%s] of type %s does not support %s", e.Field, e.Type, e.Op)
+	}
+	return fmt.Sprintf("field [%s] expects a value of type %s, got [%v]", e.Field, e.Type, e.Value)
+}
+
+// ValidateOption configures Validate
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	schema Schema
+}
+
+// WithSchema checks every field that is in the schema against its type. Literals that can be
+// converted to the field's type (e.g. "42" for an int field) are replaced with coerced copies.
+func WithSchema(schema Schema) ValidateOption {
+	return func(o *validateOptions) {
+		o.schema = schema
+	}
+}
+
+// checkSchema checks the values an expression compares its field against and coerces them into the
+// type of the field
+func checkSchema(e *Expression, schema Schema) (err error) {
+	if len(schema) == 0 || !operatesOnColumn(e.Op) {
+		return nil
+	}
+
+	field, isField := fieldName(e.Left)
+	if !isField {
+		return nil
+	}
+	typ, found := schema[field]
+	if !found {
+		return nil
+	}
+
+	unsupported := &TypeError{Field: field, Type: typ, Op: e.Op}
+	switch e.Op {
+	case Equals:
+		e.Right, err = coerceLiteral(e.Right, field, typ, e.Op)
+		return err
+	case Greater, GreaterEq, Less, LessEq:
+		if typ.kind == kindBool {
+			return unsupported
+		}
+		e.Right, err = coerceLiteral(e.Right, field, typ, e.Op)
+		return err
+	case Range:
+		if typ.kind == kindBool {
+			return unsupported
+		}
+		boundary, isBoundary := e.Right.(*RangeBoundary)
+		if !isBoundary {
+			return nil
+		}
+		b := *boundary
+		for _, bound := range []*any{&b.Min, &b.Max} {
+			if sub, isExpr := (*bound).(*Expression); isExpr && sub.Op == Literal && sub.Left == "*" {
+				continue
+			}
+			*bound, err = coerceLiteral(*bound, field, typ, e.Op)
+			if err != nil {
+				return err
+			}
+		}
+		e.Right = &b
+	case Like:
+		// patterns only make sense against text
+		if typ.kind != kindString && typ.kind != kindEnum {
+			return unsupported
+		}
+	case In:
+		list, isList := e.Right.(*Expression)
+		if !isList {
+			return nil
+		}
+		items, _ := list.Left.([]*Expression)
+		coerced := make([]*Expression, 0, len(items))
+		for _, item := range items {
+			out, err := coerceLiteral(item, field, typ, e.Op)
+			if err != nil {
+				return err
+			}
+			coerced = append(coerced, out.(*Expression))
+		}
+		cp := *list
+		cp.Left = coerced
+		e.Right = &cp
+	}
+	return nil
+}
+
+// coerceLiteral returns a copy of the literal expression with its value converted into the field type.
+// The literal itself is left alone since it can be shared with other expressions. Anything that isn't
+// a literal is returned as is.
+func coerceLiteral(in any, field string, typ FieldType, op Operator) (out any, err error) {
+	e, isExpr := in.(*Expression)
+	if !isExpr || e.Op != Literal {
+		return in, nil
+	}
+
+	val := e.Left
+	if col, isCol := val.(Column); isCol {
+		val = string(col)
+	}
+
+	coerced, ok := coerce(val, typ, op)
+	if !ok {
+		return in, &TypeError{Field: field, Type: typ, Op: op, Value: val}
+	}
+	cp := *e
+	cp.Left = coerced
+	return &cp, nil
+}
+
+// coerce converts a literal value into the type of a field. It returns false if the value can't be
+// represented by the type. String fields take any value when checking for equality, but ranges and
+// comparisons need a string since numbers and dates don't order the same way as text.
+func coerce(val any, typ FieldType, op Operator) (out any, ok bool) {
+	switch typ.kind {
+	case kindInt:
+		switch v := val.(type) {
+		case int:
+			return v, true
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, false
+			}
+			return int(v), true
+		case string:
+			i, err := strconv.Atoi(v)
+			return i, err == nil
+		}
+	case kindFloat:
+		switch v := val.(type) {
+		case int:
+			return float64(v), true
+		case float64:
+			return v, true
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			return f, err == nil
+		}
+	case kindBool:
+		switch v := val.(type) {
+		case bool:
+			return v, true
+		case string:
+			b, err := strconv.ParseBool(v)
+			return b, err == nil
+		}
+	case kindDate:
+		switch v := val.(type) {
+		case Date, time.Time:
+			return v, true
+		case string:
+			d, err := ParseDate(v)
+			return d, err == nil
+		}
+	case kindEnum:
+		s := fmt.Sprintf("%v", val)
+		for _, allowed := range typ.values {
+			if s == allowed {
+				return s, true
+			}
+		}
+	default:
+		if _, isString := val.(string); !isString && op != Equals && op != In {
+			return nil, false
+		}
+		return fmt.Sprintf("%v", val), true
+	}
+	return nil, false
+}
+
+// fieldName returns the name of the field a column expression refers to
+func fieldName(in any) (field string, ok bool) {
+	e, isExpr := in.(*Expression)
+	if !isExpr || e.Op != Literal {
+		return field, false
+	}
+
+	switch v := e.Left.(type) {
+	case Column:
+		return string(v), true
+	case string:
+		return v, true
+	}
+	return field, false
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 )
 
 type validator = func(*Expression) (err error)
@@ -28,6 +29,8 @@ var validators = map[Operator]validator{
 	Like:      validateLike,
 	In:        validateIn,
 	List:      validateList,
+	Proximity: validateProximity,
+	Exists:    validateExists,
 }
 
 func validateEquals(e *Expression) (err error) {
@@ -211,6 +214,38 @@ func validateFuzzy(e *Expression) (err error) {
 	return nil
 }
 
+func validateProximity(e *Expression) (err error) {
+	if e == nil {
+		return nil
+	}
+
+	if e.Left == nil {
+		return errors.New("PROXIMITY validation: sub expression must not be nil")
+	}
+
+	if e.Right != nil {
+		return errors.New("PROXIMITY validation: must not have two sub expressions")
+	}
+
+	return nil
+}
+
+func validateExists(e *Expression) (err error) {
+	if e == nil {
+		return nil
+	}
+
+	if e.Left == nil {
+		return errors.New("EXISTS validation: field must not be nil")
+	}
+
+	if e.Right != nil {
+		return errors.New("EXISTS validation: must not have a right side")
+	}
+
+	return nil
+}
+
 func validateLiteral(e *Expression) (err error) {
 	if e == nil {
 		return nil
@@ -350,7 +385,6 @@ func validateList(e *Expression) (err error) {
 }
 
 func isListOfLiteralExprs(in any) bool {
-	fmt.Println(reflect.TypeOf(in))
 	e, isList := in.([]*Expression)
 	if !isList {
 		return false
@@ -369,7 +403,16 @@ func isLiteralExpr(in any) bool {
 }
 
 func isLiteral(in any) bool {
-	return isString(in) || isNum(in) || isBool(in) || isColumn(in)
+	return isString(in) || isNum(in) || isBool(in) || isColumn(in) || isDate(in)
+}
+
+func isDate(in any) bool {
+	switch in.(type) {
+	case Date, time.Time:
+		return true
+	default:
+		return false
+	}
 }
 
 func isColumn(in any) bool {
@@ -0,0 +1,82 @@
+package expr
+
+// Cursor describes where an expression is in the tree during a Walk
+type Cursor struct {
+	Expr   *Expression // the expression being visited
+	Parent *Expression // the expression that contains it, nil for the root
+	Depth  int         // how far the expression is from the root, starting at 0
+}
+
+// WalkFunc is called for each expression visited by Walk
+type WalkFunc func(c Cursor) bool
+
+// Walk traverses the expression depth first, calling pre before an expression's children are
+// visited and post after. Either function may be nil.
+//
+// If pre returns false the children of the expression are skipped and post is not called for it.
+// If post returns false the walk stops entirely.
+//
+// The children of an expression are its Left and Right sides in that order, the values of a list
+// and the bounds of a range. Raw values like the string in a literal are not visited.
+func Walk(e *Expression, pre, post WalkFunc) {
+	walk(Cursor{Expr: e}, pre, post)
+}
+
+func walk(c Cursor, pre, post WalkFunc) bool {
+	if c.Expr == nil {
+		return true
+	}
+
+	if pre != nil && !pre(c) {
+		return true
+	}
+
+	for _, child := range Children(c.Expr) {
+		if !walk(Cursor{Expr: child, Parent: c.Expr, Depth: c.Depth + 1}, pre, post) {
+			return false
+		}
+	}
+
+	if post != nil {
+		return post(c)
+	}
+	return true
+}
+
+// Inspect traverses the expression in pre-order calling fn for every expression. If fn returns false
+// the children of that expression are skipped.
+func Inspect(e *Expression, fn func(*Expression) bool) {
+	Walk(e, func(c Cursor) bool { return fn(c.Expr) }, nil)
+}
+
+// Children returns the sub expressions of an expression in the order they are walked
+func Children(e *Expression) (children []*Expression) {
+	if e == nil {
+		return nil
+	}
+
+	for _, side := range []any{e.Left, e.Right} {
+		switch v := side.(type) {
+		case *Expression:
+			if v != nil {
+				children = append(children, v)
+			}
+		case []*Expression:
+			for _, sub := range v {
+				if sub != nil {
+					children = append(children, sub)
+				}
+			}
+		case *RangeBoundary:
+			if v == nil {
+				continue
+			}
+			for _, bound := range []any{v.Min, v.Max} {
+				if sub, isExpr := bound.(*Expression); isExpr && sub != nil {
+					children = append(children, sub)
+				}
+			}
+		}
+	}
+	return children
+}
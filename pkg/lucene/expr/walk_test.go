@@ -0,0 +1,113 @@
+package expr
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	type tc struct {
+		input *Expression
+		skip  Operator // pre returns false for expressions with this operator
+		stop  Operator // post returns false for expressions with this operator
+		want  []string
+	}
+
+	tcs := map[string]tc{
+		"single_literal": {
+			input: Lit("a"),
+			want:  []string{"pre LITERAL 0", "post LITERAL 0"},
+		},
+		"nested": {
+			input: AND(Eq("a", "b"), NOT(Lit("c"))),
+			want: []string{
+				"pre AND 0",
+				"pre EQUALS 1", "pre LITERAL 2", "post LITERAL 2", "pre LITERAL 2", "post LITERAL 2", "post EQUALS 1",
+				"pre NOT 1", "pre LITERAL 2", "post LITERAL 2", "post NOT 1",
+				"post AND 0",
+			},
+		},
+		"range_bounds": {
+			input: Rang("a", 1, 5, true),
+			want: []string{
+				"pre RANGE 0",
+				"pre LITERAL 1", "post LITERAL 1",
+				"pre LITERAL 1", "post LITERAL 1",
+				"pre LITERAL 1", "post LITERAL 1",
+				"post RANGE 0",
+			},
+		},
+		"list_values": {
+			input: IN("a", LIST(Lit("b"), Lit("c"))),
+			want: []string{
+				"pre IN 0",
+				"pre LITERAL 1", "post LITERAL 1",
+				"pre LIST 1", "pre LITERAL 2", "post LITERAL 2", "pre LITERAL 2", "post LITERAL 2", "post LIST 1",
+				"post IN 0",
+			},
+		},
+		"skip_subtree": {
+			input: OR(Eq("a", "b"), Lit("c")),
+			skip:  Equals,
+			want: []string{
+				"pre OR 0",
+				"pre EQUALS 1",
+				"pre LITERAL 1", "post LITERAL 1",
+				"post OR 0",
+			},
+		},
+		"stop_walk": {
+			input: OR(NOT(Lit("a")), Lit("b")),
+			stop:  Not,
+			want: []string{
+				"pre OR 0",
+				"pre NOT 1", "pre LITERAL 2", "post LITERAL 2", "post NOT 1",
+			},
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := []string{}
+			parents := map[*Expression]*Expression{}
+			Walk(
+				tc.input,
+				func(c Cursor) bool {
+					got = append(got, fmt.Sprintf("pre %s %d", c.Expr.Op, c.Depth))
+					parents[c.Expr] = c.Parent
+					return tc.skip == Undefined || c.Expr.Op != tc.skip
+				},
+				func(c Cursor) bool {
+					got = append(got, fmt.Sprintf("post %s %d", c.Expr.Op, c.Depth))
+					return tc.stop == Undefined || c.Expr.Op != tc.stop
+				},
+			)
+
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf(errTemplate, "walk order doesn't match", tc.want, got)
+			}
+
+			for child, parent := range parents {
+				if parent == nil {
+					if child != tc.input {
+						t.Fatalf("only the root should have no parent, got %#v", child)
+					}
+					continue
+				}
+				if !contains(Children(parent), child) {
+					t.Fatalf("%#v is not a child of its parent %#v", child, parent)
+				}
+			}
+		})
+	}
+}
+
+func contains(exprs []*Expression, e *Expression) bool {
+	for _, sub := range exprs {
+		if sub == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package expr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// WildcardToRegexp converts a lucene wildcard pattern into an anchored regular expression. * matches
+// any number of characters, ? matches a single character and a backslash escapes the next character.
+func WildcardToRegexp(pattern string) string {
+	var sb strings.Builder
+	sb.WriteRune('^')
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			sb.WriteString(".*")
+		case r == '?':
+			sb.WriteRune('.')
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteRune('$')
+	return sb.String()
+}
@@ -0,0 +1,26 @@
+package expr
+
+import "testing"
+
+func TestWildcardToRegexp(t *testing.T) {
+	type tc struct {
+		input string
+		want  string
+	}
+
+	tcs := map[string]tc{
+		"star":           {input: "fo*", want: "^fo.*$"},
+		"question_mark":  {input: "f?o", want: "^f.o$"},
+		"escaped_star":   {input: `a\*b`, want: `^a\*b$`},
+		"regexp_symbols": {input: "a.b+c", want: `^a\.b\+c$`},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			got := WildcardToRegexp(tc.input)
+			if got != tc.want {
+				t.Fatalf(errTemplate, "regexp doesn't match", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,483 @@
+package normalize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+// MaxClauses is the most clauses a conversion to CNF or DNF is allowed to produce. Both forms
+// can be exponentially larger than the input so the conversion gives up past this size.
+const MaxClauses = 1024
+
+// ErrTooComplex is returned when converting to CNF or DNF would produce more than MaxClauses clauses
+var ErrTooComplex = errors.New("expression is too complex to convert to a normal form")
+
+// Option configures Normalize
+type Option func(*options)
+
+type form int
+
+const (
+	formNone form = iota
+	formCNF
+	formDNF
+)
+
+type options struct {
+	form form
+}
+
+// WithCNF converts the expression into conjunctive normal form, an AND of ORs.
+func WithCNF() Option {
+	return func(o *options) {
+		o.form = formCNF
+	}
+}
+
+// WithDNF converts the expression into disjunctive normal form, an OR of ANDs.
+func WithDNF() Option {
+	return func(o *options) {
+		o.form = formDNF
+	}
+}
+
+// Normalize simplifies the boolean structure of an expression without changing what it matches.
+// It
+//   - drops MUST wrappers and turns MUST_NOT into NOT since they only matter for scoring
+//   - eliminates double negation and pushes NOTs down to the leaves using De Morgan's laws
+//   - flattens chains of AND and OR and removes duplicate clauses
+//   - merges comparisons and ranges on the same field, intersecting them under an AND and
+//     joining overlapping ones under an OR
+//
+// The input is not modified. Use WithCNF or WithDNF to also convert the result into a normal form.
+func Normalize(e *expr.Expression, opts ...Option) (out *expr.Expression, err error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if e == nil {
+		return nil, nil
+	}
+
+	// work on a copy so we can reuse the nodes without touching the input
+	out, err = expr.Rewrite(e, func(c expr.Cursor) *expr.Expression { return c.Expr })
+	if err != nil {
+		return nil, err
+	}
+
+	out = simplify(out)
+
+	switch o.form {
+	case formCNF:
+		out, err = normalForm(out, expr.And, expr.Or)
+	case formDNF:
+		out, err = normalForm(out, expr.Or, expr.And)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = expr.Validate(out)
+	if err != nil {
+		return nil, fmt.Errorf("normalized expression is invalid: %w", err)
+	}
+	return out, nil
+}
+
+func simplify(e *expr.Expression) *expr.Expression {
+	switch e.Op {
+	case expr.Must:
+		return simplify(sub(e.Left))
+	case expr.MustNot, expr.Not:
+		return negate(sub(e.Left))
+	case expr.And, expr.Or:
+		operands := []*expr.Expression{}
+		for _, side := range []*expr.Expression{sub(e.Left), sub(e.Right)} {
+			operands = append(operands, flatten(simplify(side), e.Op)...)
+		}
+		return join(e.Op, operands)
+	case expr.Boost, expr.Fuzzy, expr.Proximity:
+		if left := sub(e.Left); left != nil {
+			e.Left = simplify(left)
+		}
+	}
+	return e
+}
+
+// negate returns the simplified negation of the expression with the NOT pushed as far down as it goes
+func negate(e *expr.Expression) *expr.Expression {
+	switch e.Op {
+	case expr.Not, expr.MustNot:
+		return simplify(sub(e.Left))
+	case expr.Must:
+		return negate(sub(e.Left))
+	case expr.And:
+		return simplify(expr.OR(expr.NOT(sub(e.Left)), expr.NOT(sub(e.Right))))
+	case expr.Or:
+		return simplify(expr.AND(expr.NOT(sub(e.Left)), expr.NOT(sub(e.Right))))
+	}
+	return expr.NOT(simplify(e))
+}
+
+// flatten returns the operands of a chain of the same associative operator
+func flatten(e *expr.Expression, op expr.Operator) []*expr.Expression {
+	if e.Op != op {
+		return []*expr.Expression{e}
+	}
+	return append(flatten(sub(e.Left), op), flatten(sub(e.Right), op)...)
+}
+
+// join combines the operands with the operator after removing duplicates and merging ranges
+func join(op expr.Operator, operands []*expr.Expression) *expr.Expression {
+	operands = dedupe(mergeRanges(op, operands))
+
+	out := operands[0]
+	for _, operand := range operands[1:] {
+		out = expr.Expr(out, op, operand)
+	}
+	return out
+}
+
+func dedupe(operands []*expr.Expression) []*expr.Expression {
+	out := make([]*expr.Expression, 0, len(operands))
+	for _, operand := range operands {
+		duplicate := false
+		for _, seen := range out {
+			if reflect.DeepEqual(seen, operand) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			out = append(out, operand)
+		}
+	}
+	return out
+}
+
+// normalForm converts the expression into an outer operator of inner operators, e.g. an AND of ORs
+// for CNF. The expression must already be simplified so NOTs are only on the leaves.
+func normalForm(e *expr.Expression, outer, inner expr.Operator) (out *expr.Expression, err error) {
+	clauses, err := distribute(e, outer, inner)
+	if err != nil {
+		return nil, err
+	}
+
+	joined := make([]*expr.Expression, 0, len(clauses))
+	for _, clause := range clauses {
+		joined = append(joined, join(inner, clause))
+	}
+	return join(outer, joined), nil
+}
+
+// distribute returns the clauses of the normal form, each clause being the operands of the inner operator
+func distribute(e *expr.Expression, outer, inner expr.Operator) (clauses [][]*expr.Expression, err error) {
+	switch e.Op {
+	case outer:
+		left, err := distribute(sub(e.Left), outer, inner)
+		if err != nil {
+			return nil, err
+		}
+		right, err := distribute(sub(e.Right), outer, inner)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(left, right...)
+	case inner:
+		left, err := distribute(sub(e.Left), outer, inner)
+		if err != nil {
+			return nil, err
+		}
+		right, err := distribute(sub(e.Right), outer, inner)
+		if err != nil {
+			return nil, err
+		}
+		if len(left)*len(right) > MaxClauses {
+			return nil, ErrTooComplex
+		}
+		for _, l := range left {
+			for _, r := range right {
+				clause := append(append([]*expr.Expression{}, l...), r...)
+				clauses = append(clauses, clause)
+			}
+		}
+	default:
+		clauses = [][]*expr.Expression{{e}}
+	}
+
+	if len(clauses) > MaxClauses {
+		return nil, ErrTooComplex
+	}
+	return clauses, nil
+}
+
+// interval is a comparison or range on a field
+type interval struct {
+	field    expr.Column
+	min, max *expr.Expression // nil when the side is unbounded
+	minIncl  bool
+	maxIncl  bool
+	source   *expr.Expression // the expression the interval came from, nil once it has been merged
+}
+
+// mergeRanges merges the comparisons and ranges on the same field. Under an AND they are intersected
+// and under an OR the ones that overlap are joined. Fields with bounds that can't be ordered against
+// each other are left alone.
+func mergeRanges(op expr.Operator, operands []*expr.Expression) []*expr.Expression {
+	byField := map[expr.Column][]interval{}
+	for _, operand := range operands {
+		if iv, ok := intervalOf(operand); ok {
+			byField[iv.field] = append(byField[iv.field], iv)
+		}
+	}
+
+	merged := map[expr.Column][]*expr.Expression{}
+	for field, ivs := range byField {
+		if len(ivs) < 2 || !orderable(ivs) {
+			continue
+		}
+
+		var out []interval
+		if op == expr.And {
+			out = []interval{intersect(ivs)}
+		} else {
+			out = union(ivs)
+		}
+		if len(out) == len(ivs) {
+			continue
+		}
+
+		for _, iv := range out {
+			merged[field] = append(merged[field], iv.expr())
+		}
+	}
+
+	out := make([]*expr.Expression, 0, len(operands))
+	for _, operand := range operands {
+		iv, ok := intervalOf(operand)
+		if !ok {
+			out = append(out, operand)
+			continue
+		}
+
+		replacement, isMerged := merged[iv.field]
+		switch {
+		case !isMerged:
+			out = append(out, operand)
+		case replacement != nil:
+			// the merged intervals go where the first interval on the field was
+			out = append(out, replacement...)
+			merged[iv.field] = nil
+		}
+	}
+	return out
+}
+
+func intervalOf(e *expr.Expression) (iv interval, ok bool) {
+	left := sub(e.Left)
+	if left == nil {
+		return iv, false
+	}
+	field, isField := left.Left.(expr.Column)
+	if !isField {
+		return iv, false
+	}
+	iv = interval{field: field, source: e}
+
+	switch e.Op {
+	case expr.Greater, expr.GreaterEq:
+		iv.min, iv.minIncl = sub(e.Right), e.Op == expr.GreaterEq
+	case expr.Less, expr.LessEq:
+		iv.max, iv.maxIncl = sub(e.Right), e.Op == expr.LessEq
+	case expr.Range:
+		boundary, isBoundary := e.Right.(*expr.RangeBoundary)
+		if !isBoundary {
+			return iv, false
+		}
+		iv.min, iv.minIncl = bound(boundary.Min), boundary.MinInclusive
+		iv.max, iv.maxIncl = bound(boundary.Max), boundary.MaxInclusive
+	default:
+		return iv, false
+	}
+
+	for _, b := range []*expr.Expression{iv.min, iv.max} {
+		if b != nil && b.Op != expr.Literal {
+			return iv, false
+		}
+	}
+	return iv, true
+}
+
+// bound returns the literal for a side of a range or nil if it is unbounded
+func bound(in any) *expr.Expression {
+	e := sub(in)
+	if e == nil || e.Left == "*" {
+		return nil
+	}
+	return e
+}
+
+func (iv interval) expr() *expr.Expression {
+	if iv.source != nil {
+		return iv.source
+	}
+
+	switch {
+	case iv.min == nil && iv.max == nil:
+		return expr.EXISTS(iv.field)
+	case iv.max == nil && iv.minIncl:
+		return expr.GREATEREQ(iv.field, iv.min)
+	case iv.max == nil:
+		return expr.GREATER(iv.field, iv.min)
+	case iv.min == nil && iv.maxIncl:
+		return expr.LESSEQ(iv.field, iv.max)
+	case iv.min == nil:
+		return expr.LESS(iv.field, iv.max)
+	}
+	return expr.RangBounds(iv.field, iv.min, iv.max, iv.minIncl, iv.maxIncl)
+}
+
+// intersect returns the interval that is in all of the intervals
+func intersect(ivs []interval) interval {
+	out := ivs[0]
+	out.source = nil
+	for _, iv := range ivs[1:] {
+		if iv.min != nil {
+			c := compareBound(iv.min, out.min, true)
+			if c > 0 || (c == 0 && !iv.minIncl) {
+				out.min, out.minIncl = iv.min, iv.minIncl
+			}
+		}
+		if iv.max != nil {
+			c := compareBound(iv.max, out.max, false)
+			if c < 0 || (c == 0 && !iv.maxIncl) {
+				out.max, out.maxIncl = iv.max, iv.maxIncl
+			}
+		}
+	}
+	return out
+}
+
+// union joins the intervals that overlap or touch
+func union(ivs []interval) []interval {
+	sorted := append([]interval{}, ivs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		c := compareBound(sorted[i].min, sorted[j].min, true)
+		return c < 0 || (c == 0 && sorted[i].minIncl && !sorted[j].minIncl)
+	})
+
+	out := []interval{sorted[0]}
+	for _, iv := range sorted[1:] {
+		last := &out[len(out)-1]
+
+		c := compareBound(iv.min, last.max, false)
+		if c > 0 || (c == 0 && !iv.minIncl && !last.maxIncl) {
+			out = append(out, iv)
+			continue
+		}
+
+		last.source = nil
+		c = compareBound(iv.max, last.max, false)
+		if c > 0 || (c == 0 && iv.maxIncl) {
+			last.max, last.maxIncl = iv.max, iv.maxIncl
+		}
+	}
+	return out
+}
+
+// compareBound orders two bounds where nil is unbounded. An unbounded min is below everything and an
+// unbounded max is above everything.
+func compareBound(a, b *expr.Expression, isMin bool) int {
+	unbounded := 1
+	if isMin {
+		unbounded = -1
+	}
+
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return unbounded
+	case b == nil:
+		return -unbounded
+	}
+
+	c, _ := compare(a.Left, b.Left)
+	return c
+}
+
+// orderable checks that all of the bounds can be ordered against each other
+func orderable(ivs []interval) bool {
+	var first *expr.Expression
+	for _, iv := range ivs {
+		for _, b := range []*expr.Expression{iv.min, iv.max} {
+			if b == nil {
+				continue
+			}
+			if first == nil {
+				first = b
+			}
+			if _, ok := compare(first.Left, b.Left); !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func compare(a, b any) (int, bool) {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return strings.Compare(x, y), ok
+	case time.Time:
+		y, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x.Before(y):
+			return -1, true
+		case x.After(y):
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func toFloat(in any) (float64, bool) {
+	switch v := in.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func sub(in any) *expr.Expression {
+	e, _ := in.(*expr.Expression)
+	return e
+}
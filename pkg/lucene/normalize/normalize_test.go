@@ -0,0 +1,133 @@
+package normalize
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/grindlemire/go-lucene/pkg/lucene/expr"
+)
+
+const errTemplate = "%s:\n    wanted %#v\n    got    %#v"
+
+func TestNormalize(t *testing.T) {
+	type tc struct {
+		input *expr.Expression
+		opts  []Option
+		want  *expr.Expression
+	}
+
+	tcs := map[string]tc{
+		"leaf_is_unchanged": {
+			input: expr.Eq("a", "b"),
+			want:  expr.Eq("a", "b"),
+		},
+		"double_negation": {
+			input: expr.NOT(expr.NOT(expr.Eq("a", "b"))),
+			want:  expr.Eq("a", "b"),
+		},
+		"must_wrappers": {
+			input: expr.AND(expr.MUST(expr.Eq("a", "b")), expr.MUSTNOT(expr.Eq("c", "d"))),
+			want:  expr.AND(expr.Eq("a", "b"), expr.NOT(expr.Eq("c", "d"))),
+		},
+		"de_morgan": {
+			input: expr.NOT(expr.AND(expr.Eq("a", "b"), expr.OR(expr.Eq("c", "d"), expr.NOT(expr.Eq("e", "f"))))),
+			want: expr.OR(
+				expr.NOT(expr.Eq("a", "b")),
+				expr.AND(expr.NOT(expr.Eq("c", "d")), expr.Eq("e", "f")),
+			),
+		},
+		"flatten_and_dedupe": {
+			input: expr.OR(expr.OR(expr.Eq("a", 1), expr.Eq("b", 2)), expr.OR(expr.Eq("a", 1), expr.Eq("c", 3))),
+			want:  expr.OR(expr.OR(expr.Eq("a", 1), expr.Eq("b", 2)), expr.Eq("c", 3)),
+		},
+		"duplicate_collapses_to_one": {
+			input: expr.OR(expr.Eq("a", 1), expr.Eq("a", 1)),
+			want:  expr.Eq("a", 1),
+		},
+		"boost_is_kept": {
+			input: expr.BOOST(expr.NOT(expr.NOT(expr.Eq("a", "b"))), 2.0),
+			want:  expr.BOOST(expr.Eq("a", "b"), 2.0),
+		},
+		"intersect_comparisons": {
+			input: expr.AND(expr.AND(expr.GREATER("a", 5), expr.Eq("b", "c")), expr.LESSEQ("a", 10)),
+			want:  expr.AND(expr.RangBounds("a", 5, 10, false, true), expr.Eq("b", "c")),
+		},
+		"intersect_ranges": {
+			input: expr.AND(expr.Rang("a", 1, 10, true), expr.RangBounds("a", 5, "*", false, true)),
+			want:  expr.RangBounds("a", 5, 10, false, true),
+		},
+		"union_overlapping_ranges": {
+			input: expr.OR(expr.OR(expr.Rang("a", 1, 5, true), expr.Rang("a", 20, 30, true)), expr.Rang("a", 3, 8, true)),
+			want:  expr.OR(expr.Rang("a", 1, 8, true), expr.Rang("a", 20, 30, true)),
+		},
+		"union_touching_ranges": {
+			input: expr.OR(expr.RangBounds("a", 1, 5, true, false), expr.GREATEREQ("a", 5)),
+			want:  expr.GREATEREQ("a", 1),
+		},
+		"disjoint_ranges_are_kept": {
+			input: expr.OR(expr.LESS("a", 5), expr.GREATER("a", 5)),
+			want:  expr.OR(expr.LESS("a", 5), expr.GREATER("a", 5)),
+		},
+		"mixed_types_are_kept": {
+			input: expr.AND(expr.GREATER("a", 5), expr.LESS("a", "z")),
+			want:  expr.AND(expr.GREATER("a", 5), expr.LESS("a", "z")),
+		},
+		"cnf": {
+			input: expr.OR(expr.AND(expr.Eq("a", 1), expr.Eq("b", 2)), expr.Eq("c", 3)),
+			opts:  []Option{WithCNF()},
+			want:  expr.AND(expr.OR(expr.Eq("a", 1), expr.Eq("c", 3)), expr.OR(expr.Eq("b", 2), expr.Eq("c", 3))),
+		},
+		"dnf": {
+			input: expr.AND(expr.OR(expr.Eq("a", 1), expr.Eq("b", 2)), expr.NOT(expr.OR(expr.Eq("c", 3), expr.Eq("d", 4)))),
+			opts:  []Option{WithDNF()},
+			want: expr.OR(
+				expr.AND(expr.AND(expr.Eq("a", 1), expr.NOT(expr.Eq("c", 3))), expr.NOT(expr.Eq("d", 4))),
+				expr.AND(expr.AND(expr.Eq("b", 2), expr.NOT(expr.Eq("c", 3))), expr.NOT(expr.Eq("d", 4))),
+			),
+		},
+	}
+
+	for name, tc := range tcs {
+		t.Run(name, func(t *testing.T) {
+			original := tc.input.String()
+
+			got, err := Normalize(tc.input, tc.opts...)
+			if err != nil {
+				t.Fatalf("wanted no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Fatalf(errTemplate, "normalized expression doesn't match", tc.want, got)
+			}
+
+			if tc.input.String() != original {
+				t.Fatalf(errTemplate, "input expression was modified", original, tc.input.String())
+			}
+		})
+	}
+}
+
+func TestNormalizeTooComplex(t *testing.T) {
+	// an AND of 11 ORs has 2^11 clauses in DNF
+	var e *expr.Expression
+	for i := 0; i < 11; i++ {
+		clause := expr.OR(expr.Eq(fmt.Sprintf("a%d", i), 1), expr.Eq(fmt.Sprintf("b%d", i), 2))
+		if e == nil {
+			e = clause
+			continue
+		}
+		e = expr.AND(e, clause)
+	}
+
+	_, err := Normalize(e, WithDNF())
+	if !errors.Is(err, ErrTooComplex) {
+		t.Fatalf("expected ErrTooComplex, got: %v", err)
+	}
+
+	_, err = Normalize(e, WithCNF())
+	if err != nil {
+		t.Fatalf("wanted no error converting to CNF, got: %v", err)
+	}
+}
@@ -60,23 +60,75 @@ func equal(elems []any, nonTerminals []lex.Token) ([]any, []lex.Token, bool) {
 		return elems, nonTerminals, false
 	}
 
+	// _exists_:field checks that the field has a value
+	if name, isStr := term.Left.(string); isStr && term.Op == expr.Literal && name == "_exists_" && value.Op == expr.Literal {
+		elems = []any{expr.EXISTS(value)}
+		// we consumed one terminal, the =
+		return elems, drop(nonTerminals, 1), true
+	}
+
+	elems = []any{distributeField(term, value)}
+	// we consumed one terminal, the =
+	return elems, drop(nonTerminals, 1), true
+}
+
+// distributeField applies a field to a grouped sub expression like title:(+foo -"bar baz") so every
+// leaf in the group becomes a comparison on the field. Chained ORs of literals are collapsed into an
+// IN list and expressions that already have a field are left alone.
+func distributeField(term, value *expr.Expression) *expr.Expression {
 	if literals, ok := isChainedOrLiterals(value); ok && len(literals) > 1 {
-		elems = []any{
-			expr.IN(
-				term,
-				expr.LIST(literals),
-			),
+		return expr.IN(
+			term,
+			expr.LIST(literals),
+		)
+	}
+
+	switch value.Op {
+	case expr.And, expr.Or:
+		left, isLeftExpr := value.Left.(*expr.Expression)
+		right, isRightExpr := value.Right.(*expr.Expression)
+		if !isLeftExpr || !isRightExpr {
+			break
 		}
-	} else {
-		elems = []any{
-			expr.Eq(
-				term,
-				value,
-			),
+		return expr.Expr(distributeField(term, left), value.Op, distributeField(term, right))
+	case expr.Not, expr.Must, expr.MustNot:
+		sub, isExpr := value.Left.(*expr.Expression)
+		if !isExpr {
+			break
+		}
+		return expr.Expr(distributeField(term, sub), value.Op)
+	case expr.Boost:
+		sub, isExpr := value.Left.(*expr.Expression)
+		if !isExpr {
+			break
+		}
+		return expr.BOOST(distributeField(term, sub), value.BoostPower())
+	case expr.Fuzzy:
+		sub, isExpr := value.Left.(*expr.Expression)
+		if !isExpr {
+			break
 		}
+		return expr.FUZZY(distributeField(term, sub), value.FuzzyDistance())
+	case expr.Proximity:
+		sub, isExpr := value.Left.(*expr.Expression)
+		if !isExpr {
+			break
+		}
+		return expr.PROXIMITY(distributeField(term, sub), value.ProximityDistance())
+	case expr.Wild:
+		// field:* matches any value so it is an existence check rather than a pattern
+		if value.Left == "*" {
+			return expr.EXISTS(term)
+		}
+	case expr.Equals, expr.Like, expr.In, expr.Range, expr.Greater, expr.GreaterEq, expr.Less, expr.LessEq, expr.Exists:
+		// the expression already has its own field so the group's field doesn't apply
+		return value
 	}
-	// we consumed one terminal, the =
-	return elems, drop(nonTerminals, 1), true
+
+	return expr.Eq(
+		term,
+		value,
+	)
 }
 
 func isChainedOrLiterals(in *expr.Expression) (out []*expr.Expression, ok bool) {
@@ -483,8 +535,8 @@ func rangeop(elems []any, nonTerminals []lex.Token) ([]any, []lex.Token, bool) {
 	}
 
 	// we consumed four terminals, the :, [, TO, and ]
-	return []any{expr.Rang(
-		term, start, end, (open.Typ == lex.TLSquare && closed.Typ == lex.TRSquare),
+	return []any{expr.RangBounds(
+		term, start, end, open.Typ == lex.TLSquare, closed.Typ == lex.TRSquare,
 	)}, drop(nonTerminals, 4), true
 }
 
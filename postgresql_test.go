@@ -10,15 +10,22 @@ import (
 func TestPostgresSQLEndToEnd(t *testing.T) {
 	type tc struct {
 		input string
+		opts  []Option
 		want  string
 		err   string
 	}
 
 	tcs := map[string]tc{
-		// "single_literal": {
-		// 	input: "a",
-		// 	want:  "a",
-		// },
+		"single_literal": {
+			input: "a",
+			opts:  []Option{WithDefaultField("body")},
+			want:  "body = 'a'",
+		},
+		"literals_with_multiple_default_fields": {
+			input: `a:b "honey crisp"`,
+			opts:  []Option{WithDefaultField("title", "body")},
+			want:  "(a = 'b') AND ((title = 'honey crisp') OR (body = 'honey crisp'))",
+		},
 		"basic_equal": {
 			input: "a:b",
 			want:  "a = 'b'",
@@ -131,6 +138,26 @@ func TestPostgresSQLEndToEnd(t *testing.T) {
 			input: `a:{"ab" TO "az"}`,
 			want:  "a BETWEEN 'ab' AND 'az'",
 		},
+		"exists": {
+			input: "_exists_:a",
+			want:  "a IS NOT NULL",
+		},
+		"exists_wildcard": {
+			input: "a:* AND b:c",
+			want:  "(a IS NOT NULL) AND (b = 'c')",
+		},
+		"not_exists": {
+			input: "NOT _exists_:a OR -b:*",
+			want:  "(a IS NULL) OR (b IS NULL)",
+		},
+		"range_operator_half_open": {
+			input: `age:[18 TO 65}`,
+			want:  "age >= 18 AND age < 65",
+		},
+		"range_operator_half_open_strings": {
+			input: `a:{ab TO az]`,
+			want:  "a > 'ab' AND a <= 'az'",
+		},
 		"range_operator_exclusive_unbound": {
 			input: `a:{2 TO *}`,
 			want:  "a > 2",
@@ -151,6 +178,10 @@ func TestPostgresSQLEndToEnd(t *testing.T) {
 			input: "a:(foo OR baz OR bar)",
 			want:  "a IN ('foo', 'baz', 'bar')",
 		},
+		"field_grouping": {
+			input: `title:(+return +"pink panther" -foo*)`,
+			want:  "((title = 'return') AND (title = 'pink panther')) AND (NOT(title SIMILAR TO 'foo%'))",
+		},
 		"basic_must": {
 			input: "+a:b",
 			want:  "a = 'b'",
@@ -224,7 +255,7 @@ func TestPostgresSQLEndToEnd(t *testing.T) {
 	for name, tc := range tcs {
 		t.Run(name, func(t *testing.T) {
 
-			expr, err := Parse(tc.input)
+			expr, err := Parse(tc.input, tc.opts...)
 			if err != nil {
 				t.Fatal(err)
 			}